@@ -3,9 +3,53 @@ package main
 import (
 	"fmt"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Leveled logging. CodinGame takes a single file, so this block is copied as is into every solver.
+//
+// Only errors are logged by default, so nothing floods stderr on submission. Run with CG_LOG=info or CG_LOG=debug
+// locally, or bump logDefault for the CodinGame IDE.
+type logLevel int
+
+const (
+	lvlError logLevel = iota
+	lvlInfo
+	lvlDebug
+
+	logDefault = lvlError
+)
+
+var (
+	logLvl = parseLogLevel(os.Getenv("CG_LOG"))
+	// Every line is prefixed with it, solvers bump it every turn.
+	logTurn = 0
+)
+
+func parseLogLevel(lvl string) logLevel {
+	switch lvl {
+	case "error":
+		return lvlError
+	case "info":
+		return lvlInfo
+	case "debug":
+		return lvlDebug
+	}
+	return logDefault
+}
+
+func logf(lvl logLevel, format string, a ...interface{}) {
+	if lvl > logLvl {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%03d] %s\n", logTurn, fmt.Sprintf(format, a...))
+}
+
 type BikeStatus struct {
 	x      int
 	lane   int
@@ -13,6 +57,15 @@ type BikeStatus struct {
 	isDead bool
 }
 
+type objective int
+
+const (
+	// Fewest turns, as long as bikesToSurvive bikes make it.
+	objectiveFastest objective = iota
+	// Most bikes alive, then fewest turns. bikesToSurvive is still a hard minimum.
+	objectiveMaxSurvivors
+)
+
 type BridgeSolver struct {
 	bridge [][]bool
 	bikes  []BikeStatus
@@ -20,208 +73,561 @@ type BridgeSolver struct {
 	bikesToSurvive int
 	bridgeLength   int
 
-	tmpSpeed int
+	objective objective
+	// Order in which ops are tried, defaultOpOrder if empty.
+	opOrder []string
+	// Time for planning in a single turn, no limit if 0.
+	turnBudget time.Duration
+	// Search every first op in its own goroutine. Gives the same plans as the sequential search.
+	parallel bool
+
+	// Formations expanded by the last findPath.
+	expanded int
 }
 
-func (b *BridgeSolver) Run() {
+// readTurn reads the referee's view of the bikes into b.bikes. It returns false when there is nothing more to read
+// (game is over).
+func (b *BridgeSolver) readTurn() bool {
 	var speed int
-	fmt.Scan(&speed)
+	if _, err := fmt.Scan(&speed); err != nil {
+		return false
+	}
 	for i := range b.bikes {
-		var isDestroyed int
-		fmt.Scan(&b.bikes[i].x, &b.bikes[i].lane, &isDestroyed)
-		b.bikes[i].isDead = isDestroyed == 0
+		var isActive int
+		fmt.Scan(&b.bikes[i].x, &b.bikes[i].lane, &isActive)
+		b.bikes[i].isDead = isActive == 0
 		b.bikes[i].speed = speed
 	}
+	return true
+}
 
-	b.tmpSpeed = speed
-	sequence, ok := b.findPath([]string{}, b.bikes, "WAIT")
-	if !ok {
-		panic("Sorry, no way through this bridge ):")
+// isAsExpected checks if the referee's reported state is what our simulation predicted. Position of dead bikes
+// does not matter.
+func (b *BridgeSolver) isAsExpected(expected []BikeStatus) bool {
+	for i, bike := range b.bikes {
+		if bike.isDead != expected[i].isDead {
+			return false
+		}
+		if !bike.isDead && bike != expected[i] {
+			return false
+		}
 	}
+	return true
+}
+
+// plan returns ops to execute from now on. If there was no time to find the whole way through the bridge, it's just the
+// first op of the best partial plan and we plan again next turn.
+func (b *BridgeSolver) plan() []string {
+	var deadline time.Time
+	if b.turnBudget > 0 {
+		deadline = time.Now().Add(b.turnBudget)
+	}
+
+	var before runtime.MemStats
+	if logLvl >= lvlDebug {
+		runtime.ReadMemStats(&before)
+	}
+	began := time.Now()
+
+	sequence, complete := b.findPath(b.bikes, deadline)
+
+	if logLvl >= lvlDebug {
+		// Parsed by 'sim bench', keep the format.
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		logf(lvlDebug, "Plan stats: expanded=%d allocs=%d bytes=%d took=%v",
+			b.expanded, after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc, time.Since(began))
+	}
+
+	if !complete {
+		if len(sequence) == 0 {
+			// Whatever we do, we lose bikes we need. Let's at least lose the fewest.
+			sequence = []string{b.leastBadOp(formationOf(b.bikes))}
+		}
+
+		logf(lvlInfo, "No full plan yet, going with %v", sequence)
+		return sequence[:1]
+	}
+
+	if len(sequence) == 0 {
+		// Already behind the bridge, just keep going.
+		sequence = []string{"SPEED"}
+	}
+
+	logf(lvlInfo, "Lets run! %v", sequence)
+	return sequence
+}
+
+func (b *BridgeSolver) Run() {
+	var (
+		sequence []string
+		expected []BikeStatus
+	)
+	for b.readTurn() {
+		logTurn++
+		if expected != nil && !b.isAsExpected(expected) {
+			logf(lvlInfo, "Reality diverged from plan! Expected %v, got %v. Replanning.", expected, b.bikes)
+			sequence = nil
+		}
+
+		if len(sequence) == 0 {
+			sequence = b.plan()
+		}
+
+		op := sequence[0]
+		sequence = sequence[1:]
+		expected = b.simBikes(b.bikes, op)
 
-	fmt.Fprintln(os.Stderr, fmt.Sprintf("Lets run! %v", sequence))
-	for _, op := range sequence {
 		// A single line containing one of 6 keywords: SPEED, SLOW, JUMP, WAIT, UP, DOWN.
 		fmt.Println(op)
+	}
+}
+
+// How an op changes the bike before it moves forward by its speed.
+type opRule struct {
+	speedDelta int
+	laneDelta  int
+	jump       bool
+}
+
+var opRules = map[string]opRule{
+	"SPEED": {speedDelta: 1},
+	"SLOW":  {speedDelta: -1},
+	"JUMP":  {jump: true},
+	"WAIT":  {},
+	"UP":    {laneDelta: -1},
+	"DOWN":  {laneDelta: 1},
+}
+
+func (b *BridgeSolver) isHole(lane, x int) bool {
+	// Behind the bridge there is only road.
+	if x >= b.bridgeLength {
+		return false
+	}
+	return !b.bridge[lane][x]
+}
+
+func isOnBridge(lane int) bool {
+	return lane >= 0 && lane < 4
+}
 
-		var speed int
-		fmt.Scan(&speed)
-		for _, bike := range b.bikes {
-			var isDestroyed int
-			fmt.Scan(&bike.x, &bike.lane, &isDestroyed)
-			bike.isDead = isDestroyed == 0
-			bike.speed = speed
+// effectiveOp returns the op referee really applies for given bikes. If any of the alive bikes can't change the lane,
+// none of them does, so UP and DOWN becomes WAIT.
+func effectiveOp(lanes []int, op string) string {
+	for _, lane := range lanes {
+		if !isOnBridge(lane + opRules[op].laneDelta) {
+			return "WAIT"
 		}
 	}
+	return op
 }
 
 func (b *BridgeSolver) simBikes(bikes []BikeStatus, op string) []BikeStatus {
-	newBikes := append([]BikeStatus{}, bikes...)
+	var lanes []int
+	for _, bike := range bikes {
+		if !bike.isDead {
+			lanes = append(lanes, bike.lane)
+		}
+	}
+	op = effectiveOp(lanes, op)
 
+	newBikes := append([]BikeStatus{}, bikes...)
 	for i, bike := range newBikes {
-		if bike.isDead {
-			continue
-		}
 		newBikes[i] = b.simBike(bike, op)
 	}
 	return newBikes
 }
 
+// simBike moves a single bike by the rules, without side effects:
+//   - speed never goes below 0,
+//   - bike can't leave the bridge with UP or DOWN (it goes straight then),
+//   - when going straight, every cell up to the landing one must be a road,
+//   - when changing lane, the cells in between are checked on both lanes, the landing one only on the new lane,
+//   - when jumping, only the landing cell is checked.
 func (b *BridgeSolver) simBike(bike BikeStatus, op string) BikeStatus {
-	oldLane := -1
-	switch op {
-	case "SPEED":
-		bike.speed++
-	case "SLOW":
-		bike.speed--
-	case "UP":
-		oldLane = bike.lane
-		bike.lane--
-	case "DOWN":
-		oldLane = bike.lane
-		bike.lane++
-	}
-
-	// Any hole in the meantime?
-	if op != "JUMP" {
-		for i := bike.x + 1; i < bike.x+bike.speed; i++ {
-			if !b.bridge[bike.lane][i] {
-				bike.isDead = true
-			}
+	if bike.isDead {
+		return bike
+	}
 
-			if oldLane > -1 {
-				if !b.bridge[oldLane][i] {
-					bike.isDead = true
-				}
+	rule := opRules[op]
+	bike.speed += rule.speedDelta
+	if bike.speed < 0 {
+		bike.speed = 0
+	}
+
+	newLane := bike.lane + rule.laneDelta
+	if !isOnBridge(newLane) {
+		newLane = bike.lane
+	}
+
+	if !rule.jump {
+		for x := bike.x + 1; x < bike.x+bike.speed; x++ {
+			if b.isHole(bike.lane, x) || b.isHole(newLane, x) {
+				bike.isDead = true
 			}
 		}
 	}
 
-	if !b.bridge[bike.lane][bike.x+bike.speed] {
+	if b.isHole(newLane, bike.x+bike.speed) {
 		bike.isDead = true
 	}
 
 	bike.x += bike.speed
-	b.tmpSpeed = bike.speed
+	bike.lane = newLane
 	return bike
 }
 
+// isFinish is the referee's rule: the last cell of the bridge is bridgeLength-1, so a bike at bridgeLength is over.
 func (b *BridgeSolver) isFinish(x int) bool {
-	return x > b.bridgeLength
+	return x >= b.bridgeLength
+}
+
+// CodinGame ends the game after this many turns, no point to search deeper.
+const maxTurns = 50
+
+// Default order in which ops are tried. It matters only for choosing between equally good sequences, but thanks to
+// that the same input always gives the same plan.
+var defaultOpOrder = []string{"SPEED", "WAIT", "SLOW", "JUMP", "UP", "DOWN"}
+
+// formation is a hashable state of all bikes. Bikes always share x and speed, so only the lanes of alive bikes
+// matter, not which bike is which.
+type formation struct {
+	x     int
+	speed int
+	lanes uint8 // Bit per lane with alive bike on it.
+}
+
+func formationOf(bikes []BikeStatus) formation {
+	f := formation{}
+	for _, bike := range bikes {
+		if bike.isDead {
+			continue
+		}
+		f.x, f.speed = bike.x, bike.speed
+		f.lanes |= 1 << uint(bike.lane)
+	}
+	return f
+}
+
+func (f formation) alive() int {
+	alive := 0
+	for lane := uint(0); lane < 4; lane++ {
+		if f.lanes&(1<<lane) != 0 {
+			alive++
+		}
+	}
+	return alive
 }
 
-// Naive, recursive.
-func (b *BridgeSolver) findPath(sequence []string, bikes []BikeStatus, previousOp string) ([]string, bool) {
-	optionsToCheck := map[string]int{
-		"SPEED": 0, "SLOW": 2, "WAIT": 1, "JUMP": 3, "UP": 4, "DOWN": 5,
+func (f formation) isFurtherThan(other formation) bool {
+	if f.x != other.x {
+		return f.x > other.x
 	}
+	return f.alive() > other.alive()
+}
+
+func (f formation) hasLane(lane int) bool {
+	return f.lanes&(1<<uint(lane)) != 0
+}
 
-	if b.tmpSpeed <= 1 {
-		delete(optionsToCheck, "SLOW")
+func (f formation) laneList() []int {
+	var lanes []int
+	for lane := 0; lane < 4; lane++ {
+		if f.hasLane(lane) {
+			lanes = append(lanes, lane)
+		}
 	}
+	return lanes
+}
+
+func (b *BridgeSolver) simFormation(f formation, op string) formation {
+	lanes := f.laneList()
+	op = effectiveOp(lanes, op)
 
-	if b.tmpSpeed <= 0 {
-		delete(optionsToCheck, "WAIT")
+	next := formation{}
+	for _, lane := range lanes {
+		bike := b.simBike(BikeStatus{x: f.x, lane: lane, speed: f.speed}, op)
+		next.x, next.speed = bike.x, bike.speed
+		if !bike.isDead {
+			next.lanes |= 1 << uint(bike.lane)
+		}
 	}
+	return next
+}
 
-	if previousOp == "UP" {
-		delete(optionsToCheck, "DOWN")
-	} else if previousOp == "DOWN" {
-		delete(optionsToCheck, "UP")
+// legalOps returns ops worth trying for the whole formation, in b.opOrder. Referee accepts any op, but:
+//   - UP and DOWN are ignored when any of the alive bikes can't change the lane, so they are just a WAIT then,
+//   - SLOW and WAIT on speed 0 do nothing at all.
+func (b *BridgeSolver) legalOps(f formation) []string {
+	order := b.opOrder
+	if len(order) == 0 {
+		order = defaultOpOrder
 	}
 
-	possibleOpsPerBike := []map[string]struct{}{}
-	for _, bike := range bikes {
-		if bike.isDead {
+	lanes := f.laneList()
+	var ops []string
+	for _, op := range order {
+		if effectiveOp(lanes, op) != op {
+			continue
+		}
+		if f.speed == 0 && (op == "SLOW" || op == "WAIT") {
 			continue
 		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+type searchNode struct {
+	formation
+	parent int
+	op     string
+	depth  int
+}
+
+// searchResult is what a single BFS found. Best is the index of the best complete sequence (-1 if none), partial
+// of the best partial one.
+type searchResult struct {
+	nodes    []searchNode
+	best     int
+	partial  int
+	expanded int
+}
+
+// findPath is a BFS over bike formations, so sequences are found from the shortest ones. Formations already seen
+// are never expanded again. Which of the found sequences wins depends on b.objective.
+//
+// It stops at deadline (if not zero). Sequence is complete only if it reaches the end of the bridge, otherwise it is
+// the best partial one: the furthest, then with the most bikes alive.
+func (b *BridgeSolver) findPath(bikes []BikeStatus, deadline time.Time) (sequence []string, complete bool) {
+	start := formationOf(bikes)
+	if b.isFinish(start.x) {
+		return []string{}, true
+	}
+
+	var res searchResult
+	if b.parallel {
+		res = b.searchParallel(start, deadline)
+	} else {
+		root := searchNode{formation: start, parent: -1}
+		res = b.search(root, start, deadline, nil)
+	}
+	b.expanded = res.expanded
 
-		// FINISH.
-		if b.isFinish(bike.x) {
-			fmt.Fprintln(os.Stderr, "Found path!")
-			return sequence, true
+	if res.best < 0 {
+		return pathTo(res.nodes, res.partial), false
+	}
+
+	logf(lvlDebug, "Found path with %d bikes! Checked %d formations.", res.nodes[res.best].alive(), len(res.nodes))
+	return pathTo(res.nodes, res.best), true
+}
+
+// search runs BFS from root. Start is the formation we plan from, it may be root's parent when called for a single
+// branch. Cutoff, if not nil, is shared with other branches: depth of a sequence nobody can beat, so nodes that deep are
+// not worth expanding.
+func (b *BridgeSolver) search(root searchNode, start formation, deadline time.Time, cutoff *int32) searchResult {
+	res := searchResult{nodes: []searchNode{root}, best: -1}
+	if b.isFinish(root.x) {
+		res.best = 0
+		return res
+	}
+
+	visited := map[formation]struct{}{start: {}, root.formation: {}}
+	for i := 0; i < len(res.nodes); i++ {
+		if !deadline.IsZero() && i%256 == 0 && time.Now().After(deadline) {
+			logf(lvlInfo, "Out of time! Checked %d formations.", len(res.nodes))
+			break
 		}
 
-		if bike.lane == 0 {
-			delete(optionsToCheck, "UP")
-		} else if bike.lane == 3 {
-			delete(optionsToCheck, "DOWN")
+		node := res.nodes[i]
+		if node.depth >= maxTurns || b.isFinish(node.x) {
+			continue
+		}
+		if cutoff != nil && int32(node.depth) >= atomic.LoadInt32(cutoff) {
+			continue
+		}
+		if res.best >= 0 && node.alive() <= res.nodes[res.best].alive() {
+			// Can't beat what we have, it would be longer with the same bikes at best.
+			continue
 		}
+		res.expanded++
 
-		possibleOps := map[string]struct{}{}
-		for op := range optionsToCheck {
-			newBike := b.simBike(bike, op)
-			if !newBike.isDead {
-				possibleOps[op] = struct{}{}
+		for _, op := range b.legalOps(node.formation) {
+			next := b.simFormation(node.formation, op)
+			if next.alive() < b.bikesToSurvive {
+				continue
 			}
-		}
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = struct{}{}
+			res.nodes = append(res.nodes, searchNode{formation: next, parent: i, op: op, depth: node.depth + 1})
 
-		possibleOpsPerBike = append(possibleOpsPerBike, possibleOps)
-	}
+			if next.isFurtherThan(res.nodes[res.partial].formation) {
+				res.partial = len(res.nodes) - 1
+			}
+
+			if !b.isFinish(next.x) {
+				continue
+			}
+			if res.best >= 0 && next.alive() <= res.nodes[res.best].alive() {
+				continue
+			}
+			res.best = len(res.nodes) - 1
 
-	for op := range optionsToCheck {
-		remainAlive := 0
-		for _, bike := range possibleOpsPerBike {
-			_, ok := bike[op]
-			if ok {
-				remainAlive++
+			if b.objective == objectiveFastest || next.alive() == start.alive() {
+				if cutoff != nil {
+					lowerCutoff(cutoff, int32(node.depth+1))
+				}
+				return res
 			}
 		}
+	}
+	return res
+}
 
-		if len(possibleOpsPerBike)+remainAlive <= b.bikesToSurvive {
-			delete(optionsToCheck, op)
+func lowerCutoff(cutoff *int32, depth int32) {
+	for {
+		old := atomic.LoadInt32(cutoff)
+		if depth >= old || atomic.CompareAndSwapInt32(cutoff, old, depth) {
+			return
 		}
 	}
+}
 
-	// ORDER!
-	orderedOptionsToCheck := []string{}
-	for op, weight := range optionsToCheck {
-		if len(orderedOptionsToCheck) > 0 && optionsToCheck[orderedOptionsToCheck[0]] > weight {
-			orderedOptionsToCheck = append([]string{op}, orderedOptionsToCheck...)
+// searchParallel splits the search at the root: every first op gets its own goroutine. The first branch to find a
+// sequence nobody can beat cuts the others at its depth. Then the best branch is chosen in op order, so the result
+// is the same as of the sequential search.
+func (b *BridgeSolver) searchParallel(start formation, deadline time.Time) searchResult {
+	var roots []searchNode
+	seen := map[formation]struct{}{start: {}}
+	for _, op := range b.legalOps(start) {
+		next := b.simFormation(start, op)
+		if next.alive() < b.bikesToSurvive {
+			continue
+		}
+		if _, ok := seen[next]; ok {
+			continue
 		}
+		seen[next] = struct{}{}
+		roots = append(roots, searchNode{formation: next, parent: -1, op: op, depth: 1})
+	}
 
-		orderedOptionsToCheck = append(orderedOptionsToCheck, op)
+	cutoff := int32(maxTurns + 1)
+	results := make([]searchResult, len(roots))
+	var wg sync.WaitGroup
+	for i := range roots {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = b.search(roots[i], start, deadline, &cutoff)
+		}(i)
 	}
+	wg.Wait()
+
+	// Nothing found is the start itself, as in sequential search.
+	res := searchResult{nodes: []searchNode{{formation: start, parent: -1}}, best: -1, expanded: 1}
+	bestBranch, partialBranch := -1, -1
+	for i, r := range results {
+		res.expanded += r.expanded
+		if r.best >= 0 && (bestBranch < 0 || b.isBetterPlan(r.nodes[r.best], results[bestBranch].nodes[results[bestBranch].best])) {
+			bestBranch = i
+		}
 
-	for _, op := range orderedOptionsToCheck {
-		// GO routines?
-		fmt.Fprintln(os.Stderr, fmt.Sprintf("Checking op %v on seq %v", op, sequence))
-		newSequence, ok := b.findPath(append(sequence, op), b.simBikes(bikes, op), op)
-		if ok {
-			return newSequence, true
+		p := r.nodes[r.partial]
+		if partialBranch < 0 {
+			if p.isFurtherThan(start) {
+				partialBranch = i
+			}
+			continue
+		}
+		bestPartial := results[partialBranch].nodes[results[partialBranch].partial]
+		isTie := !p.isFurtherThan(bestPartial.formation) && !bestPartial.isFurtherThan(p.formation)
+		if p.isFurtherThan(bestPartial.formation) || (isTie && p.depth < bestPartial.depth) {
+			partialBranch = i
 		}
 	}
 
-	return []string{}, false
+	switch {
+	case bestBranch >= 0:
+		r := results[bestBranch]
+		res.nodes, res.best, res.partial = r.nodes, r.best, r.partial
+	case partialBranch >= 0:
+		r := results[partialBranch]
+		res.nodes, res.partial = r.nodes, r.partial
+	}
+	return res
 }
 
-func main() {
-	var bikeNum int
-	var bikesToSurvive int
-	fmt.Scan(&bikeNum)
-	fmt.Scan(&bikesToSurvive)
+func (b *BridgeSolver) isBetterPlan(n, other searchNode) bool {
+	if b.objective == objectiveMaxSurvivors && n.alive() != other.alive() {
+		return n.alive() > other.alive()
+	}
+	return n.depth < other.depth
+}
 
-	b := BridgeSolver{bikesToSurvive: bikesToSurvive}
-	for i := 0; i < 4; i++ {
-		var line string
-		fmt.Scan(&line)
-		fmt.Fprintln(os.Stderr, line)
+// leastBadOp is the last resort, when every op loses more bikes than we can afford.
+func (b *BridgeSolver) leastBadOp(f formation) string {
+	bestOp := ""
+	var best formation
+	for _, op := range b.legalOps(f) {
+		next := b.simFormation(f, op)
+		if bestOp == "" || next.alive() > best.alive() || (next.alive() == best.alive() && next.x > best.x) {
+			bestOp, best = op, next
+		}
+	}
+	return bestOp
+}
+
+func pathTo(nodes []searchNode, i int) []string {
+	sequence := make([]string, nodes[i].depth)
+	for ; i >= 0; i = nodes[i].parent {
+		if nodes[i].depth > 0 {
+			sequence[nodes[i].depth-1] = nodes[i].op
+		}
+	}
+	return sequence
+}
+
+// newBridgeSolver builds the solver from the initial input: lanes as '.' road and '0' hole.
+func newBridgeSolver(lanes []string, bikeNum, bikesToSurvive int) *BridgeSolver {
+	b := &BridgeSolver{
+		bikesToSurvive: bikesToSurvive,
+		objective:      objectiveMaxSurvivors,
+		opOrder:        defaultOpOrder,
+	}
+	for i, line := range lanes {
 		b.bridge = append(b.bridge, []bool{})
 		for _, char := range strings.Split(line, "") {
 			b.bridge[i] = append(b.bridge[i], char == ".")
 		}
 
 		b.bridgeLength = len(b.bridge[i])
-
-		// Fake bridge, just for sim to work.
-		for x := b.bridgeLength - 1; x < b.bridgeLength+50; x++ {
-			b.bridge[i] = append(b.bridge[i], true)
-		}
 	}
 
 	for i := 0; i < bikeNum; i++ {
 		b.bikes = append(b.bikes, BikeStatus{})
 	}
+	return b
+}
+
+func main() {
+	var bikeNum int
+	var bikesToSurvive int
+	fmt.Scan(&bikeNum)
+	fmt.Scan(&bikesToSurvive)
+
+	var lanes []string
+	for i := 0; i < 4; i++ {
+		var line string
+		fmt.Scan(&line)
+		logf(lvlDebug, "%s", line)
+		lanes = append(lanes, line)
+	}
 
+	b := newBridgeSolver(lanes, bikeNum, bikesToSurvive)
+	b.turnBudget = 100 * time.Millisecond // CodinGame gives 150ms per turn.
+	// CG_PARALLEL=1 turns on searchParallel, e.g. for 'sim bench'. Plans are the same.
+	b.parallel, _ = strconv.ParseBool(os.Getenv("CG_PARALLEL"))
 	b.Run()
 }
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Run with 'go test bridge.go bridge_test.go', there is no go.mod. Fuzz with
+//
+//	go test -run XXX -fuzz FuzzFindPath bridge.go bridge_test.go
+//
+// Failures are minimized and written to testdata/fuzz/FuzzFindPath, plain 'go test' replays them from there. Benchmark
+// with
+//
+//	go test -run XXX -bench FindPath bridge.go bridge_test.go
+
+// bike and layout are the sim's shapes (see sim/rules.go and sim/layout.go), so fixtures and rule cases read the
+// same. The sim is a separate main package, it can't be imported.
+type bike struct {
+	x     int
+	lane  int
+	alive bool
+}
+
+type layout struct {
+	lanes          []string
+	bikesToSurvive int
+	speed          int
+	bikes          []bike
+}
+
+// solver returns the solver for l, as built from the initial input, and the bikes of the first turn.
+func (l layout) solver() (*BridgeSolver, []BikeStatus) {
+	s := newBridgeSolver(l.lanes, len(l.bikes), l.bikesToSurvive)
+	bikes := make([]BikeStatus, len(l.bikes))
+	for i, b := range l.bikes {
+		bikes[i] = BikeStatus{x: b.x, lane: b.lane, speed: l.speed, isDead: !b.alive}
+	}
+	return s, bikes
+}
+
+// loadLayout reads a fixture (see fixtures/README.md), skipping its '#' lines.
+func loadLayout(path string) (layout, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return layout{}, err
+	}
+	var input []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			input = append(input, line)
+		}
+	}
+	r := strings.NewReader(strings.Join(input, "\n"))
+
+	var l layout
+	var bikeNum int
+	if _, err := fmt.Fscan(r, &bikeNum, &l.bikesToSurvive); err != nil {
+		return layout{}, fmt.Errorf("%s: %v", path, err)
+	}
+	l.lanes = make([]string, 4)
+	for i := range l.lanes {
+		if _, err := fmt.Fscan(r, &l.lanes[i]); err != nil {
+			return layout{}, fmt.Errorf("%s: lane %d: %v", path, i, err)
+		}
+	}
+	if _, err := fmt.Fscan(r, &l.speed); err != nil {
+		return layout{}, fmt.Errorf("%s: %v", path, err)
+	}
+	for i := 0; i < bikeNum; i++ {
+		var b bike
+		var active int
+		if _, err := fmt.Fscan(r, &b.x, &b.lane, &active); err != nil {
+			return layout{}, fmt.Errorf("%s: bike %d: %v", path, i, err)
+		}
+		b.alive = active == 1
+		l.bikes = append(l.bikes, b)
+	}
+	return l, nil
+}
+
+// fixtures returns all fixtures, including the official ones, by path in fixtures without the extension, e.g.
+// "official/01_easy".
+func fixtures(tb testing.TB) map[string]layout {
+	var paths []string
+	for _, pattern := range []string{"fixtures/*.txt", "fixtures/official/*.txt"} {
+		p, err := filepath.Glob(pattern)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		paths = append(paths, p...)
+	}
+	if len(paths) == 0 {
+		tb.Fatal("no fixtures, run from the directory of bridge.go")
+	}
+
+	layouts := map[string]layout{}
+	for _, path := range paths {
+		l, err := loadLayout(path)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		name, _ := filepath.Rel("fixtures", strings.TrimSuffix(path, ".txt"))
+		layouts[filepath.ToSlash(name)] = l
+	}
+	return layouts
+}
+
+// ruleCase is a single turn with its expected outcome, from fixtures/rules (see sim/rulecases.go for the format). The
+// sim referee checks its rules with the same files, here they check the solver's own move model.
+type ruleCase struct {
+	name string
+	layout
+	op string
+
+	wantSpeed int
+	wantBikes []bike
+}
+
+func loadRuleCase(path string) (ruleCase, error) {
+	l, err := loadLayout(path)
+	if err != nil {
+		return ruleCase{}, err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ruleCase{}, err
+	}
+
+	c := ruleCase{name: strings.TrimSuffix(filepath.Base(path), ".txt"), layout: l}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(line, "#"), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "name":
+			c.name = value
+		case "op":
+			c.op = value
+		case "want speed":
+			if c.wantSpeed, err = strconv.Atoi(value); err != nil {
+				return ruleCase{}, fmt.Errorf("%s: want speed: %v", path, err)
+			}
+		case "want bikes":
+			for _, b := range strings.Split(value, ",") {
+				var x, lane, active int
+				if _, err := fmt.Sscan(b, &x, &lane, &active); err != nil {
+					return ruleCase{}, fmt.Errorf("%s: want bikes %q: %v", path, b, err)
+				}
+				c.wantBikes = append(c.wantBikes, bike{x: x, lane: lane, alive: active == 1})
+			}
+		}
+	}
+	if c.op == "" || len(c.wantBikes) != len(c.bikes) {
+		return ruleCase{}, fmt.Errorf("%s: op and want bikes for all %d bikes are required", path, len(c.bikes))
+	}
+	return c, nil
+}
+
+// ruleCases returns all rule cases, sorted by file name.
+func ruleCases(tb testing.TB) []ruleCase {
+	paths, err := filepath.Glob("fixtures/rules/*.txt")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if len(paths) == 0 {
+		tb.Fatal("no rule cases, run from the directory of bridge.go")
+	}
+	sort.Strings(paths)
+
+	var cases []ruleCase
+	for _, path := range paths {
+		c, err := loadRuleCase(path)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+func TestSimBikes(t *testing.T) {
+	for _, c := range ruleCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			s, bikes := c.solver()
+
+			got := s.simBikes(bikes, c.op)
+			var gotBikes []bike
+			for i, b := range got {
+				gotBikes = append(gotBikes, bike{x: b.x, lane: b.lane, alive: !b.isDead})
+				if !bikes[i].isDead && b.speed != c.wantSpeed {
+					t.Errorf("bike %d speed %d, want %d", i, b.speed, c.wantSpeed)
+				}
+			}
+			if !reflect.DeepEqual(gotBikes, c.wantBikes) {
+				t.Errorf("bikes %v, want %v", gotBikes, c.wantBikes)
+			}
+		})
+	}
+}
+
+func TestSimFormation(t *testing.T) {
+	for _, c := range ruleCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			s, bikes := c.solver()
+
+			var want formation
+			for _, b := range c.wantBikes {
+				if b.alive {
+					want.x, want.speed = b.x, c.wantSpeed
+					want.lanes |= 1 << uint(b.lane)
+				}
+			}
+
+			got := s.simFormation(formationOf(bikes), c.op)
+			if got.lanes == 0 {
+				// Position of dead bikes does not matter.
+				got = formation{}
+			}
+			if got != want {
+				t.Errorf("formation %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// decodeLayout turns arbitrary bytes into a small, valid layout, so any fuzz input is a test case:
+//
+//	data[0] bridge length (4-19), data[1] bikes (1-4), data[2] bikes to survive, data[3] start speed (0-3),
+//	then one byte per cell, lane by lane, every 4th value is a hole.
+//
+// Missing bytes are zeros.
+func decodeLayout(data []byte) layout {
+	at := func(i int) int {
+		if i < len(data) {
+			return int(data[i])
+		}
+		return 0
+	}
+
+	length := 4 + at(0)%16
+	bikes := 1 + at(1)%4
+	l := layout{
+		bikesToSurvive: 1 + at(2)%bikes,
+		speed:          at(3) % 4,
+	}
+
+	i := 4
+	for lane := 0; lane < 4; lane++ {
+		cells := make([]byte, length)
+		for x := range cells {
+			cells[x] = '.'
+			// Bikes start on road.
+			if x > 0 && at(i)%4 == 3 {
+				cells[x] = '0'
+			}
+			i++
+		}
+		l.lanes = append(l.lanes, string(cells))
+	}
+
+	for b := 0; b < bikes; b++ {
+		l.bikes = append(l.bikes, bike{x: 0, lane: b, alive: true})
+	}
+	return l
+}
+
+// turn is the referee's move (see game.apply in sim/rules.go), written out again on purpose: the fuzz oracle must not
+// share a rule bug with the solver's simBike. TestTurn pins it with the rule cases.
+func (l layout) turn(speed int, bikes []bike, op string) (int, []bike) {
+	laneDelta := 0
+	switch op {
+	case "SPEED":
+		speed++
+	case "SLOW":
+		if speed > 0 {
+			speed--
+		}
+	case "UP":
+		laneDelta = -1
+	case "DOWN":
+		laneDelta = 1
+	}
+	// If any of the bikes can't change the lane, none of them does.
+	for _, b := range bikes {
+		if b.alive && (b.lane+laneDelta < 0 || b.lane+laneDelta > 3) {
+			laneDelta = 0
+		}
+	}
+	isHole := func(lane, x int) bool {
+		return x < len(l.lanes[0]) && l.lanes[lane][x] == '0'
+	}
+
+	next := append([]bike{}, bikes...)
+	for i, b := range bikes {
+		if !b.alive {
+			continue
+		}
+		lane := b.lane + laneDelta
+		for x := b.x + 1; op != "JUMP" && x < b.x+speed; x++ {
+			if isHole(b.lane, x) || isHole(lane, x) {
+				next[i].alive = false
+			}
+		}
+		if isHole(lane, b.x+speed) {
+			next[i].alive = false
+		}
+		next[i].x, next[i].lane = b.x+speed, lane
+	}
+	return speed, next
+}
+
+func TestTurn(t *testing.T) {
+	for _, c := range ruleCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			speed, bikes := c.turn(c.speed, c.bikes, c.op)
+			if speed != c.wantSpeed || !reflect.DeepEqual(bikes, c.wantBikes) {
+				t.Errorf("speed %d, bikes %v, want %d, %v", speed, bikes, c.wantSpeed, c.wantBikes)
+			}
+		})
+	}
+}
+
+func alive(bikes []bike) int {
+	n := 0
+	for _, b := range bikes {
+		if b.alive {
+			n++
+		}
+	}
+	return n
+}
+
+// isCrossed is the referee's rule (see game.result in sim/rules.go), not the solver's isFinish.
+func (l layout) isCrossed(bikes []bike) bool {
+	for _, b := range bikes {
+		if b.alive && b.x < len(l.lanes[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bruteForce tries every op in every reachable state, breadth first, and returns the most bikes which can cross the
+// bridge and the fewest turns to do it, or -1, -1 if there is no way. It uses the referee's rules (see turn), nothing
+// of the solver.
+func bruteForce(l layout) (maxAlive, turns int) {
+	type node struct {
+		speed int
+		bikes []bike
+		depth int
+	}
+	queue := []node{{speed: l.speed, bikes: l.bikes}}
+	seen := map[string]bool{fmt.Sprint(l.speed, l.bikes): true}
+	maxAlive, turns = -1, -1
+	for len(queue) > 0 && maxAlive < alive(l.bikes) {
+		n := queue[0]
+		queue = queue[1:]
+		if n.depth >= maxTurns {
+			continue
+		}
+
+		for _, op := range []string{"SPEED", "SLOW", "JUMP", "WAIT", "UP", "DOWN"} {
+			speed, next := l.turn(n.speed, n.bikes, op)
+			for i := range next {
+				if !next[i].alive {
+					// Where a bike died does not matter.
+					next[i] = bike{}
+				}
+			}
+
+			a := alive(next)
+			if a < l.bikesToSurvive {
+				continue
+			}
+			if l.isCrossed(next) {
+				if a > maxAlive {
+					maxAlive, turns = a, n.depth+1
+				}
+				continue
+			}
+
+			key := fmt.Sprint(speed, next)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			queue = append(queue, node{speed: speed, bikes: next, depth: n.depth + 1})
+		}
+	}
+	return maxAlive, turns
+}
+
+// FuzzFindPath plays findPath's plan by the referee's rules and compares it with bruteForce: the same bikes have to
+// make it in the same number of turns.
+func FuzzFindPath(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{12, 0, 0, 1, 0, 0, 0, 3, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	f.Add([]byte{8, 3, 1, 0, 0, 3, 0, 0, 3, 3, 0, 0, 3, 0, 0, 3, 0, 3, 3, 0, 0, 0, 3, 0, 3, 0, 0, 3, 0, 0, 3, 3})
+	f.Add([]byte{15, 2, 2, 2, 0, 0, 0, 0, 7, 7, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 7, 7, 7, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		l := decodeLayout(data)
+		wantAlive, wantTurns := bruteForce(l)
+
+		s, bikes := l.solver()
+		plan, complete := s.findPath(bikes, time.Time{})
+		if wantAlive < 0 {
+			if complete {
+				t.Fatalf("%v: no way through, but findPath found %v", l, plan)
+			}
+			return
+		}
+		if !complete {
+			t.Fatalf("%v: %d bike(s) can make it in %d turns, findPath found no way", l, wantAlive, wantTurns)
+		}
+
+		speed, played := l.speed, l.bikes
+		for i, op := range plan {
+			speed, played = l.turn(speed, played, op)
+			if alive(played) < l.bikesToSurvive {
+				t.Fatalf("%v: plan %v loses too many bikes in turn %d", l, plan, i+1)
+			}
+		}
+		if !l.isCrossed(played) {
+			t.Fatalf("%v: plan %v does not cross the bridge", l, plan)
+		}
+		if got := alive(played); got != wantAlive || len(plan) != wantTurns {
+			t.Fatalf("%v: plan %v saves %d bike(s) in %d turns, %d can make it in %d", l, plan, got, len(plan),
+				wantAlive, wantTurns)
+		}
+	})
+}
+
+// TestParallelFindPath checks that searchParallel gives exactly the same plans as the sequential search, on every
+// fixture and on random layouts. Worth running with -race as well.
+func TestParallelFindPath(t *testing.T) {
+	layouts := fixtures(t)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		data := make([]byte, 4+19*4)
+		rnd.Read(data)
+		layouts[fmt.Sprintf("random_%03d", i)] = decodeLayout(data)
+	}
+
+	for name, l := range layouts {
+		s, bikes := l.solver()
+		want, wantComplete := s.findPath(bikes, time.Time{})
+
+		s.parallel = true
+		got, gotComplete := s.findPath(bikes, time.Time{})
+		if !reflect.DeepEqual(got, want) || gotComplete != wantComplete {
+			t.Errorf("%s %v: parallel plan %v (complete %v), sequential %v (complete %v)", name, l, got, gotComplete,
+				want, wantComplete)
+		}
+	}
+}
+
+// BenchmarkFindPath plans every fixture from its first turn, without a deadline. The official test cases are
+// benchmarked as official/<name>, once they are in fixtures/official.
+func BenchmarkFindPath(b *testing.B) {
+	layouts := fixtures(b)
+	var names []string
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		l := layouts[name]
+		b.Run(name, func(b *testing.B) {
+			s, bikes := l.solver()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s.findPath(bikes, time.Time{})
+			}
+			b.ReportMetric(float64(s.expanded), "nodes/op")
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// planStats are the solver's own numbers, logged on debug level after every plan as:
+//
+//	Plan stats: expanded=807 allocs=5911 bytes=437272 took=2.399425ms
+type planStats struct {
+	plans    int
+	expanded int
+	allocs   uint64
+	bytes    uint64
+	took     time.Duration
+}
+
+func (s *planStats) add(o planStats) {
+	s.plans += o.plans
+	s.expanded += o.expanded
+	s.allocs += o.allocs
+	s.bytes += o.bytes
+	s.took += o.took
+}
+
+func parsePlanStats(log []byte) (planStats, error) {
+	var total planStats
+	s := bufio.NewScanner(bytes.NewReader(log))
+	for s.Scan() {
+		line := s.Text()
+		i := strings.Index(line, "Plan stats: ")
+		if i < 0 {
+			continue
+		}
+
+		var (
+			st   = planStats{plans: 1}
+			took string
+		)
+		if _, err := fmt.Sscanf(line[i:], "Plan stats: expanded=%d allocs=%d bytes=%d took=%s",
+			&st.expanded, &st.allocs, &st.bytes, &took); err != nil {
+			return planStats{}, fmt.Errorf("parse %q: %v", line, err)
+		}
+		d, err := time.ParseDuration(took)
+		if err != nil {
+			return planStats{}, fmt.Errorf("parse %q: %v", line, err)
+		}
+		st.took = d
+		total.add(st)
+	}
+	return total, s.Err()
+}
+
+type benchResult struct {
+	fixture fixture
+	res     result
+	// Averages per run.
+	stats planStats
+	wall  time.Duration
+}
+
+// bench plays fix count times and averages the solver's plan stats and the wall time of the whole game. The wall time
+// includes starting the solver process, BenchmarkFindPath in ../bridge_test.go measures findPath alone.
+func bench(ref referee, fix fixture, count int) (benchResult, error) {
+	ref.env = append(ref.env, "CG_LOG=debug")
+
+	br := benchResult{fixture: fix}
+	var (
+		total planStats
+		wall  time.Duration
+	)
+	for i := 0; i < count; i++ {
+		var stderr bytes.Buffer
+		ref.stderr = &stderr
+
+		began := time.Now()
+		res, err := ref.play(fix.layout)
+		if err != nil {
+			return benchResult{}, err
+		}
+		wall += time.Since(began)
+		br.res = res
+
+		st, err := parsePlanStats(stderr.Bytes())
+		if err != nil {
+			return benchResult{}, err
+		}
+		if st.plans == 0 {
+			return benchResult{}, fmt.Errorf("%s: no 'Plan stats' in the solver's stderr", fix.name)
+		}
+		total.add(st)
+	}
+
+	n := uint64(count)
+	br.stats = planStats{
+		plans:    total.plans / count,
+		expanded: total.expanded / count,
+		allocs:   total.allocs / n,
+		bytes:    total.bytes / n,
+		took:     total.took / time.Duration(count),
+	}
+	br.wall = wall / time.Duration(count)
+	return br, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fixture is a layout with metadata on top, as '# key: value' lines:
+//
+//	# name: Single hole
+//	# expect: win
+//	1
+//	1
+//	......0.....
+//	...
+//
+// Since the metadata are just comments, every fixture is a valid layout for 'sim play' as well. The layout part is
+// exactly what the solver reads to build its BridgeSolver.
+type fixture struct {
+	path string
+	name string
+	// Expected outcome, win or loss.
+	expect string
+	// If not 0, at least that many bikes are expected to cross the bridge.
+	survivors int
+
+	layout
+}
+
+func loadFixture(path string) (fixture, error) {
+	l, err := loadLayout(path)
+	if err != nil {
+		return fixture{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fixture{}, err
+	}
+	defer f.Close()
+
+	fix := fixture{
+		path:   path,
+		name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		expect: "win",
+		layout: l,
+	}
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kv := strings.SplitN(strings.TrimPrefix(line, "#"), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "name":
+			fix.name = value
+		case "expect":
+			if value != "win" && value != "loss" {
+				return fixture{}, fmt.Errorf("%s: expect has to be win or loss, got %q", path, value)
+			}
+			fix.expect = value
+		case "survivors":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fixture{}, fmt.Errorf("%s: survivors: %v", path, err)
+			}
+			fix.survivors = n
+		}
+	}
+	return fix, s.Err()
+}
+
+// loadFixtures loads all *.txt fixtures from dir, sorted by file name.
+func loadFixtures(dir string) ([]fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.txt fixtures in %s", dir)
+	}
+	sort.Strings(paths)
+
+	var fixtures []fixture
+	for _, path := range paths {
+		fix, err := loadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fix)
+	}
+	return fixtures, nil
+}
+
+func (f fixture) isExpected(res result) bool {
+	if res.won != (f.expect == "win") {
+		return false
+	}
+	return !res.won || res.alive >= f.survivors
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// smallLayout is a random layout small enough for the oracle: up to 19 cells, any number of bikes and start speed up
+// to 3. The native fuzzer in ../bridge_test.go covers the same space from bytes.
+func smallLayout(rnd *rand.Rand) layout {
+	bikes := 1 + rnd.Intn(4)
+	return randomLayout(genConfig{
+		length:  4 + rnd.Intn(16),
+		density: 0.25,
+		bikes:   bikes,
+		survive: 1 + rnd.Intn(bikes),
+		speed:   rnd.Intn(4),
+	}, rnd)
+}
+
+// verify plays l with the solver and compares the result with the oracle. The referee already makes sure every op
+// is legal and that the bridge is crossed with enough bikes, so here we only check we agree on the outcome and the
+// number of turns. Empty disagreement means all good. Error is returned only if the solver could not be run.
+func verify(ref referee, l layout) (disagreement string, err error) {
+	o := solve(l)
+	res, err := ref.play(l)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case o.solvable && !res.won:
+		return fmt.Sprintf("oracle found a way (%v: %v), solver lost: %v", o, o.ops, res), nil
+	case !o.solvable && res.won:
+		return fmt.Sprintf("oracle found no way, solver won: %v with %v", res, res.ops), nil
+	case res.won && res.alive < o.maxSurvivors:
+		return fmt.Sprintf("oracle saved %d bike(s) (%v), solver only %d with %v", o.maxSurvivors, o.ops, res.alive, res.ops), nil
+	case res.won && res.turns > o.turns:
+		return fmt.Sprintf("oracle crossed in %d turns (%v), solver took %d with %v", o.turns, o.ops, res.turns, res.ops), nil
+	}
+	return "", nil
+}
+
+// minimize simplifies l as long as the solver still disagrees with the oracle: it drops bikes, cuts cells and fills
+// holes, until none of that keeps the disagreement.
+func minimize(ref referee, l layout) (layout, string, error) {
+	disagreement, err := verify(ref, l)
+	if err != nil {
+		return layout{}, "", err
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, c := range simplifications(l) {
+			d, err := verify(ref, c)
+			if err != nil {
+				return layout{}, "", err
+			}
+			if d != "" {
+				l, disagreement, changed = c, d, true
+				break
+			}
+		}
+	}
+	return l, disagreement, nil
+}
+
+// simplifications returns every layout which is one step simpler than l.
+func simplifications(l layout) []layout {
+	var out []layout
+
+	for i := 0; i < len(l.bikes) && len(l.bikes) > l.bikesToSurvive; i++ {
+		c := l
+		c.bikes = append(append([]bike{}, l.bikes[:i]...), l.bikes[i+1:]...)
+		out = append(out, c)
+	}
+
+	if l.bikesToSurvive > 1 {
+		c := l
+		c.bikesToSurvive--
+		out = append(out, c)
+	}
+
+	for x := 1; x < len(l.lanes[0]) && len(l.lanes[0]) > 2; x++ {
+		c := l
+		c.lanes = nil
+		for _, lane := range l.lanes {
+			c.lanes = append(c.lanes, lane[:x]+lane[x+1:])
+		}
+		out = append(out, c)
+	}
+
+	for lane := range l.lanes {
+		for x := range l.lanes[lane] {
+			if l.lanes[lane][x] != '0' {
+				continue
+			}
+			c := l
+			c.lanes = append([]string{}, l.lanes...)
+			c.lanes[lane] = l.lanes[lane][:x] + "." + l.lanes[lane][x+1:]
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+type genConfig struct {
+	length int
+	// Probability of a hole on each cell. The first cells (where bikes start) and the last one are always road.
+	density float64
+	bikes   int
+	survive int
+	speed   int
+}
+
+func (c genConfig) validate() error {
+	switch {
+	case c.length < 4:
+		return fmt.Errorf("bridge has to be at least 4 cells long, got %d", c.length)
+	case c.density < 0 || c.density >= 1:
+		return fmt.Errorf("hole density has to be in [0, 1), got %v", c.density)
+	case c.bikes < 1 || c.bikes > 4:
+		return fmt.Errorf("1 to 4 bikes allowed, got %d", c.bikes)
+	case c.survive < 1 || c.survive > c.bikes:
+		return fmt.Errorf("bikes to survive has to be in [1, %d], got %d", c.bikes, c.survive)
+	case c.speed < 0:
+		return fmt.Errorf("negative start speed %d", c.speed)
+	}
+	return nil
+}
+
+// randomLayout returns a layout which is not necessarily solvable.
+func randomLayout(c genConfig, rnd *rand.Rand) layout {
+	l := layout{bikesToSurvive: c.survive, speed: c.speed}
+	for lane := 0; lane < 4; lane++ {
+		cells := make([]byte, c.length)
+		for x := range cells {
+			cells[x] = '.'
+			if x > 1 && x < c.length-1 && rnd.Float64() < c.density {
+				cells[x] = '0'
+			}
+		}
+		l.lanes = append(l.lanes, string(cells))
+	}
+
+	for _, lane := range rnd.Perm(4)[:c.bikes] {
+		l.bikes = append(l.bikes, bike{x: 0, lane: lane, alive: true})
+	}
+	return l
+}
+
+// solvableLayout draws random layouts until the oracle finds a way through one of them.
+func solvableLayout(c genConfig, rnd *rand.Rand, attempts int) (layout, oracleResult, error) {
+	if err := c.validate(); err != nil {
+		return layout{}, oracleResult{}, err
+	}
+
+	for i := 0; i < attempts; i++ {
+		l := randomLayout(c, rnd)
+		if o := solve(l); o.solvable {
+			return l, o, nil
+		}
+	}
+	return layout{}, oracleResult{}, fmt.Errorf("no solvable layout in %d attempts, try lower hole density", attempts)
+}
+
+// writeFixture writes l in the fixture format, with the oracle result and notes as comments.
+func writeFixture(w io.Writer, name string, l layout, o oracleResult, notes ...string) error {
+	expect := "loss"
+	if o.solvable {
+		expect = "win"
+	}
+
+	lines := []string{
+		"# name: " + name,
+		"# expect: " + expect,
+		"# oracle: " + o.String(),
+	}
+	if o.solvable {
+		lines = append(lines,
+			fmt.Sprintf("# survivors: %d", o.maxSurvivors),
+			"# oracle ops: "+strings.Join(o.ops, " "),
+		)
+	}
+	for _, note := range notes {
+		lines = append(lines, "# note: "+note)
+	}
+	lines = append(lines, strings.TrimSuffix(l.initInput(), "\n"))
+
+	g := newGame(l)
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n"+g.turnInput())
+	return err
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// layout is a single Bridge test case, in exactly the same shape as CodinGame gives it to the solver:
+//
+//	M           (bikes)
+//	V           (bikes to survive)
+//	L0 L1 L2 L3 (lanes, '.' road, '0' hole)
+//	S           (start speed)
+//	X Y A       (M times, position, lane and 1 if the bike is active)
+//
+// Lines starting with '#' are ignored (see fixture).
+type layout struct {
+	lanes          []string
+	bikesToSurvive int
+	speed          int
+	bikes          []bike
+}
+
+func loadLayout(path string) (layout, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return layout{}, err
+	}
+	defer f.Close()
+
+	l, err := parseLayout(f)
+	if err != nil {
+		return layout{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return l, nil
+}
+
+func parseLayout(r io.Reader) (layout, error) {
+	var content strings.Builder
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(lines.Text()), "#") {
+			continue
+		}
+		content.WriteString(lines.Text() + "\n")
+	}
+	if err := lines.Err(); err != nil {
+		return layout{}, err
+	}
+
+	s := bufio.NewScanner(strings.NewReader(content.String()))
+	s.Split(bufio.ScanWords)
+
+	next := func(what string) (string, error) {
+		if !s.Scan() {
+			if err := s.Err(); err != nil {
+				return "", err
+			}
+			return "", fmt.Errorf("unexpected end of layout, expected %s", what)
+		}
+		return s.Text(), nil
+	}
+	nextInt := func(what string) (int, error) {
+		tok, err := next(what)
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(tok)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", what, err)
+		}
+		return n, nil
+	}
+
+	var l layout
+	bikeNum, err := nextInt("bike count")
+	if err != nil {
+		return layout{}, err
+	}
+	if l.bikesToSurvive, err = nextInt("bikes to survive"); err != nil {
+		return layout{}, err
+	}
+
+	for i := 0; i < 4; i++ {
+		lane, err := next(fmt.Sprintf("lane %d", i))
+		if err != nil {
+			return layout{}, err
+		}
+		if strings.Trim(lane, ".0") != "" {
+			return layout{}, fmt.Errorf("lane %d: only '.' and '0' are allowed, got %q", i, lane)
+		}
+		if i > 0 && len(lane) != len(l.lanes[0]) {
+			return layout{}, fmt.Errorf("lane %d: length %d differs from lane 0 length %d", i, len(lane), len(l.lanes[0]))
+		}
+		l.lanes = append(l.lanes, lane)
+	}
+
+	if l.speed, err = nextInt("start speed"); err != nil {
+		return layout{}, err
+	}
+
+	for i := 0; i < bikeNum; i++ {
+		var b bike
+		if b.x, err = nextInt(fmt.Sprintf("bike %d x", i)); err != nil {
+			return layout{}, err
+		}
+		if b.lane, err = nextInt(fmt.Sprintf("bike %d lane", i)); err != nil {
+			return layout{}, err
+		}
+		active, err := nextInt(fmt.Sprintf("bike %d active", i))
+		if err != nil {
+			return layout{}, err
+		}
+		if b.lane < 0 || b.lane > 3 {
+			return layout{}, fmt.Errorf("bike %d: lane %d out of bridge", i, b.lane)
+		}
+		b.alive = active == 1
+		l.bikes = append(l.bikes, b)
+	}
+
+	if l.bikesToSurvive > bikeNum {
+		return layout{}, fmt.Errorf("%d bikes to survive, but only %d bikes", l.bikesToSurvive, bikeNum)
+	}
+	return l, nil
+}
+
+// initInput is what the solver reads once, before the first turn.
+func (l layout) initInput() string {
+	return fmt.Sprintf("%d\n%d\n%s\n", len(l.bikes), l.bikesToSurvive, strings.Join(l.lanes, "\n"))
+}
@@ -0,0 +1,385 @@
+// Command sim is a local referee for The Bridge. It runs the solver as a separate process (the same single file which
+// is submitted to CodinGame) and talks to it over stdin/stdout with exactly the same turn protocol.
+//
+// Build (there is no go.mod, so list the files):
+//
+//	go build -o /tmp/bridge ../bridge.go
+//	go build -o /tmp/bridge-sim *.go
+//
+// Usage:
+//
+//	/tmp/bridge-sim play -solver /tmp/bridge -layout ../fixtures/01_straight_line.txt
+//	/tmp/bridge-sim suite -solver /tmp/bridge -dir ../fixtures
+//	/tmp/bridge-sim render -layout ../fixtures/02_single_hole.txt -ops "SPEED SPEED JUMP"
+//	/tmp/bridge-sim gen -n 20 -length 80 -density 0.15 -bikes 4 -survive 2 -out /tmp/stress
+//	/tmp/bridge-sim fuzz -solver /tmp/bridge -n 1000
+//	/tmp/bridge-sim rules -dir ../fixtures/rules
+//	/tmp/bridge-sim bench -solver /tmp/bridge -dir ../fixtures -count 10
+//	CG_PARALLEL=1 /tmp/bridge-sim bench -solver /tmp/bridge -dir ../fixtures -count 10
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: sim <command> [flags]
+
+Commands:
+  play   Run the solver against a single layout and report win/loss.
+  suite  Run the solver against all fixtures in a directory.
+  render Draw every turn of the given op sequence.
+  gen    Generate random layouts, verified as solvable by an exhaustive search.
+  fuzz   Compare the solver with an exhaustive search on random small layouts.
+  bench  Report the solver's search effort (expanded formations, allocations, time) per fixture.
+  rules  Check the referee rules against hand-crafted turns.
+
+Run 'sim <command> -h' for command flags.`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "play":
+		err = play(os.Args[2:])
+	case "suite":
+		err = suite(os.Args[2:])
+	case "render":
+		err = render(os.Args[2:])
+	case "gen":
+		err = gen(os.Args[2:])
+	case "fuzz":
+		err = fuzz(os.Args[2:])
+	case "bench":
+		err = benchCmd(os.Args[2:])
+	case "rules":
+		err = rules(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sim:", err)
+		os.Exit(1)
+	}
+}
+
+type refereeFlags struct {
+	solver           string
+	firstTurnTimeout time.Duration
+	turnTimeout      time.Duration
+	quiet            bool
+}
+
+func registerRefereeFlags(fs *flag.FlagSet) *refereeFlags {
+	f := &refereeFlags{}
+	fs.StringVar(&f.solver, "solver", "", "Solver binary with optional args, e.g. '/tmp/bridge'.")
+	fs.DurationVar(&f.firstTurnTimeout, "first-timeout", 1*time.Second, "Time limit for the first turn.")
+	fs.DurationVar(&f.turnTimeout, "timeout", 150*time.Millisecond, "Time limit for every next turn.")
+	fs.BoolVar(&f.quiet, "quiet", false, "Drop the solver's stderr.")
+	return f
+}
+
+func (f *refereeFlags) referee() (referee, error) {
+	solver := strings.Fields(f.solver)
+	if len(solver) == 0 {
+		return referee{}, fmt.Errorf("-solver is required")
+	}
+
+	var stderr io.Writer = os.Stderr
+	if f.quiet {
+		stderr = ioutil.Discard
+	}
+	return referee{
+		solver:           solver,
+		firstTurnTimeout: f.firstTurnTimeout,
+		turnTimeout:      f.turnTimeout,
+		stderr:           stderr,
+	}, nil
+}
+
+func play(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	layoutPath := fs.String("layout", "", "Layout file in CodinGame input format.")
+	verbose := fs.Bool("v", false, "Print every turn.")
+	draw := fs.Bool("render", false, "Draw every turn.")
+	_ = fs.Parse(args)
+
+	ref, err := rf.referee()
+	if err != nil {
+		return err
+	}
+	if *verbose {
+		ref.verbose = os.Stdout
+	}
+	if *draw {
+		ref.render = os.Stdout
+	}
+
+	l, err := loadLayout(*layoutPath)
+	if err != nil {
+		return err
+	}
+
+	res, err := ref.play(l)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(res)
+	if !res.won {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func suite(args []string) error {
+	fs := flag.NewFlagSet("suite", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	dir := fs.String("dir", "../fixtures", "Directory with *.txt fixtures.")
+	_ = fs.Parse(args)
+
+	ref, err := rf.referee()
+	if err != nil {
+		return err
+	}
+
+	fixtures, err := loadFixtures(*dir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIXTURE\tEXPECT\tRESULT\t")
+	unexpected := 0
+	for _, fix := range fixtures {
+		res, err := ref.play(fix.layout)
+		if err != nil {
+			return err
+		}
+
+		status := ""
+		if !fix.isExpected(res) {
+			status = "UNEXPECTED"
+			unexpected++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", fix.name, fix.expect, res, status)
+	}
+	_ = w.Flush()
+
+	if unexpected > 0 {
+		return fmt.Errorf("%d of %d fixtures with unexpected result", unexpected, len(fixtures))
+	}
+	fmt.Printf("All %d fixtures as expected.\n", len(fixtures))
+	return nil
+}
+
+func render(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	layoutPath := fs.String("layout", "", "Layout file in CodinGame input format.")
+	ops := fs.String("ops", "", "Ops separated by commas or spaces, e.g. 'SPEED,SPEED,JUMP'.")
+	_ = fs.Parse(args)
+
+	l, err := loadLayout(*layoutPath)
+	if err != nil {
+		return err
+	}
+
+	res, err := replay(os.Stdout, l, parseOps(*ops))
+	if err != nil {
+		return err
+	}
+	fmt.Println(res)
+	return nil
+}
+
+func gen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	c := genConfig{}
+	fs.IntVar(&c.length, "length", 50, "Bridge length.")
+	fs.Float64Var(&c.density, "density", 0.1, "Probability of a hole on each cell.")
+	fs.IntVar(&c.bikes, "bikes", 4, "Number of bikes.")
+	fs.IntVar(&c.survive, "survive", 2, "Bikes which have to survive.")
+	fs.IntVar(&c.speed, "speed", 1, "Start speed.")
+	n := fs.Int("n", 1, "Number of layouts.")
+	seed := fs.Int64("seed", 1, "Random seed, the same seed gives the same layouts.")
+	attempts := fs.Int("attempts", 1000, "Random layouts to try for each solvable one.")
+	out := fs.String("out", "", "Directory for fixtures, stdout if empty.")
+	_ = fs.Parse(args)
+
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			return err
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(*seed))
+	for i := 0; i < *n; i++ {
+		l, o, err := solvableLayout(c, rnd, *attempts)
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("gen_s%d_%03d", *seed, i)
+		if *out == "" {
+			if err := writeFixture(os.Stdout, name, l, o); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f, err := os.Create(filepath.Join(*out, name+".txt"))
+		if err != nil {
+			return err
+		}
+		if err := writeFixture(f, name, l, o); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fuzz(args []string) error {
+	fs := flag.NewFlagSet("fuzz", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	n := fs.Int("n", 1000, "Number of random inputs.")
+	seed := fs.Int64("seed", 1, "Random seed, the same seed gives the same inputs.")
+	maxFailures := fs.Int("max-failures", 1, "Stop after that many disagreements.")
+	out := fs.String("out", "../fixtures", "Directory for minimized fixtures of disagreements.")
+	_ = fs.Parse(args)
+	rf.quiet = true
+
+	ref, err := rf.referee()
+	if err != nil {
+		return err
+	}
+
+	rnd := rand.New(rand.NewSource(*seed))
+	failures := 0
+	for i := 0; i < *n && failures < *maxFailures; i++ {
+		l := smallLayout(rnd)
+		disagreement, err := verify(ref, l)
+		if err != nil {
+			return err
+		}
+		if disagreement == "" {
+			continue
+		}
+		failures++
+		fmt.Printf("Input %d: %s\n", i, disagreement)
+
+		l, disagreement, err = minimize(ref, l)
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("fuzz_s%d_%d", *seed, i)
+		path := filepath.Join(*out, name+".txt")
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := writeFixture(f, name, l, solve(l), disagreement); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("Minimized to %s: %s\n", path, disagreement)
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d disagreement(s) between solver and oracle", failures)
+	}
+	fmt.Printf("No disagreements in %d inputs.\n", *n)
+	return nil
+}
+
+func benchCmd(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	dir := fs.String("dir", "../fixtures", "Directory with *.txt fixtures.")
+	count := fs.Int("count", 5, "Runs per fixture, numbers are averaged.")
+	_ = fs.Parse(args)
+
+	ref, err := rf.referee()
+	if err != nil {
+		return err
+	}
+
+	fixtures, err := loadFixtures(*dir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIXTURE\tTURNS\tPLANS\tEXPANDED\tALLOCS\tKB\tPLAN TIME\tWALL\tRESULT\t")
+	var total planStats
+	for _, fix := range fixtures {
+		br, err := bench(ref, fix, *count)
+		if err != nil {
+			return err
+		}
+		total.add(br.stats)
+
+		result := "WIN"
+		if !br.res.won {
+			result = "LOSS"
+		}
+		if !fix.isExpected(br.res) {
+			result += " (UNEXPECTED)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%v\t%v\t%s\t\n",
+			fix.name, br.res.turns, br.stats.plans, br.stats.expanded, br.stats.allocs, br.stats.bytes/1024,
+			br.stats.took.Round(time.Microsecond), br.wall.Round(time.Microsecond), result)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t%d\t%d\t%d\t%d\t%v\t\t\t\n",
+		total.plans, total.expanded, total.allocs, total.bytes/1024, total.took.Round(time.Microsecond))
+	return w.Flush()
+}
+
+func rules(args []string) error {
+	fs := flag.NewFlagSet("rules", flag.ExitOnError)
+	dir := fs.String("dir", "../fixtures/rules", "Directory with *.txt rule cases.")
+	_ = fs.Parse(args)
+
+	ruleCases, err := loadRuleCases(*dir)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, c := range ruleCases {
+		if err := c.check(); err != nil {
+			fmt.Printf("FAIL %s: %v\n", c.name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("ok   %s\n", c.name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rule cases failed", failed, len(ruleCases))
+	}
+	return nil
+}
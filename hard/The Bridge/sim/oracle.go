@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+)
+
+// oracleResult is the ground truth for a layout.
+type oracleResult struct {
+	solvable bool
+	// Most bikes which can cross the bridge, then fewest turns to do it.
+	maxSurvivors int
+	turns        int
+	ops          []string
+}
+
+func (o oracleResult) String() string {
+	if !o.solvable {
+		return "unsolvable"
+	}
+	return fmt.Sprintf("%d bike(s) in %d turns", o.maxSurvivors, o.turns)
+}
+
+type oracleNode struct {
+	g      *game
+	parent int
+	op     string
+}
+
+// solve is an exhaustive reference search: BFS over every op in every reachable game state within maxTurns, using
+// the referee rules only. It shares nothing with the solver on purpose. Identical states (speed and all bikes) are
+// expanded once.
+func solve(l layout) oracleResult {
+	all := []string{"SPEED", "SLOW", "JUMP", "WAIT", "UP", "DOWN"}
+
+	start := newGame(l)
+	nodes := []oracleNode{{g: start, parent: -1}}
+	visited := map[string]struct{}{start.turnInput(): {}}
+
+	res := oracleResult{}
+	best := -1
+	for i := 0; i < len(nodes); i++ {
+		if over, _, _ := nodes[i].g.result(); over {
+			continue
+		}
+
+		for _, op := range all {
+			g := nodes[i].g.clone()
+			_ = g.apply(op)
+
+			key := g.turnInput()
+			if _, ok := visited[key]; ok {
+				continue
+			}
+			visited[key] = struct{}{}
+			nodes = append(nodes, oracleNode{g: g, parent: i, op: op})
+
+			if _, won, _ := g.result(); won && (best < 0 || g.alive() > nodes[best].g.alive()) {
+				best = len(nodes) - 1
+			}
+		}
+
+		if best >= 0 && nodes[best].g.alive() == start.alive() {
+			// Can't be better.
+			break
+		}
+	}
+
+	if best < 0 {
+		return res
+	}
+
+	res.solvable = true
+	res.maxSurvivors = nodes[best].g.alive()
+	res.turns = nodes[best].g.turns
+	for i := best; nodes[i].parent >= 0; i = nodes[i].parent {
+		res.ops = append([]string{nodes[i].op}, res.ops...)
+	}
+	return res
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type result struct {
+	won    bool
+	turns  int
+	alive  int
+	reason string
+	ops    []string
+}
+
+func (r result) String() string {
+	if r.won {
+		return fmt.Sprintf("WIN after %d turns, %d bike(s) alive", r.turns, r.alive)
+	}
+	return fmt.Sprintf("LOSS after %d turns: %s", r.turns, r.reason)
+}
+
+type referee struct {
+	solver []string
+
+	firstTurnTimeout time.Duration
+	turnTimeout      time.Duration
+
+	// Extra environment for the solver, e.g. CG_LOG=debug.
+	env []string
+	// Solver's stderr goes here.
+	stderr io.Writer
+	// If not nil, every turn is reported here.
+	verbose io.Writer
+	// If not nil, every turn is drawn here.
+	render io.Writer
+}
+
+// play runs a fresh solver process through the whole game. Solver failures (timeout, invalid command, crash) are
+// a lost game, not an error. Error is returned only if the solver could not be started.
+func (ref referee) play(l layout) (result, error) {
+	cmd := exec.Command(ref.solver[0], ref.solver[1:]...)
+	cmd.Stderr = ref.stderr
+	if len(ref.env) > 0 {
+		cmd.Env = append(os.Environ(), ref.env...)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return result{}, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return result{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return result{}, fmt.Errorf("start solver %v: %v", ref.solver, err)
+	}
+	defer func() {
+		_ = stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		s := bufio.NewScanner(stdout)
+		for s.Scan() {
+			lines <- strings.TrimSpace(s.Text())
+		}
+	}()
+
+	g := newGame(l)
+	res := result{}
+	finish := func(reason string) (result, error) {
+		res.turns = g.turns
+		res.alive = g.alive()
+		res.reason = reason
+		return res, nil
+	}
+
+	// Writes can fail only if the solver is already gone, which is reported on the next read anyway.
+	_, _ = io.WriteString(stdin, l.initInput())
+	timeout := ref.firstTurnTimeout
+	for {
+		_, _ = io.WriteString(stdin, g.turnInput())
+
+		var op string
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return finish("solver exited")
+			}
+			op = line
+		case <-time.After(timeout):
+			return finish(fmt.Sprintf("timeout, no command within %v", timeout))
+		}
+		timeout = ref.turnTimeout
+
+		before := append([]bike{}, g.bikes...)
+		if err := g.apply(op); err != nil {
+			return finish(err.Error())
+		}
+		res.ops = append(res.ops, op)
+
+		if ref.render != nil {
+			renderTurn(ref.render, g, before, op)
+		}
+		if ref.verbose != nil {
+			fmt.Fprintf(ref.verbose, "Turn %d: %-5s speed %d, bikes (x lane alive) %v\n", g.turns, op, g.speed, g.bikes)
+		}
+
+		if over, won, reason := g.result(); over {
+			res.won = won
+			return finish(reason)
+		}
+	}
+}
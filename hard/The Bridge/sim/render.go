@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Bikes are drawn as A, B, C, D (in input order), a bike which died in this turn as 'x'. Dead bikes from previous
+// turns are not drawn at all.
+func bikeName(i int) string {
+	return string(rune('A' + i))
+}
+
+// renderTurn draws the bridge after op was applied. Before is the bikes state from before op.
+func renderTurn(w io.Writer, g *game, before []bike, op string) {
+	fmt.Fprintf(w, "Turn %d: %s (speed %d)\n", g.turns, op, g.speed)
+	renderBridge(w, g, before)
+
+	for i, b := range g.bikes {
+		if before[i].alive && !b.alive {
+			fmt.Fprintf(w, "  Bike %s died moving from x=%d lane %d to x=%d lane %d.\n",
+				bikeName(i), before[i].x, before[i].lane, b.x, b.lane)
+		}
+	}
+}
+
+func renderBridge(w io.Writer, g *game, before []bike) {
+	width := g.length()
+	for _, b := range g.bikes {
+		if b.x+1 > width {
+			width = b.x + 1
+		}
+	}
+
+	for lane := 0; lane < 4; lane++ {
+		row := []rune(g.lanes[lane] + strings.Repeat(" ", width-g.length()))
+		for i, b := range g.bikes {
+			if b.lane != lane {
+				continue
+			}
+			switch {
+			case b.alive:
+				row[b.x] = rune(bikeName(i)[0])
+			case before != nil && before[i].alive:
+				row[b.x] = 'x'
+			}
+		}
+		fmt.Fprintf(w, "  %s\n", string(row))
+	}
+}
+
+// replay renders the whole game for given ops, the same way the referee would play it.
+func replay(w io.Writer, l layout, ops []string) (result, error) {
+	g := newGame(l)
+	fmt.Fprintf(w, "Start (speed %d)\n", g.speed)
+	renderBridge(w, g, nil)
+
+	res := result{}
+	for _, op := range ops {
+		before := append([]bike{}, g.bikes...)
+		if err := g.apply(op); err != nil {
+			return result{}, err
+		}
+		res.ops = append(res.ops, op)
+		renderTurn(w, g, before, op)
+
+		if over, won, reason := g.result(); over {
+			res.won, res.reason = won, reason
+			break
+		}
+	}
+
+	if over, _, _ := g.result(); !over {
+		res.reason = "out of ops, bridge not crossed"
+	}
+	res.turns = g.turns
+	res.alive = g.alive()
+	return res, nil
+}
+
+// parseOps accepts ops separated by commas or whitespace, also straight from the solver log, e.g. "[SPEED JUMP]".
+func parseOps(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(", \n\t[]", r)
+	})
+}
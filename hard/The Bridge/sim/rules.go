@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CodinGame ends the game after this many turns.
+const maxTurns = 50
+
+type bike struct {
+	x     int
+	lane  int
+	alive bool
+}
+
+// game is the referee state. It is deliberately independent from the solver code, so the solver can't cheat
+// by sharing bugs with the referee.
+type game struct {
+	lanes          []string
+	bikesToSurvive int
+	speed          int
+	bikes          []bike
+
+	turns int
+}
+
+func newGame(l layout) *game {
+	return &game{
+		lanes:          l.lanes,
+		bikesToSurvive: l.bikesToSurvive,
+		speed:          l.speed,
+		bikes:          append([]bike{}, l.bikes...),
+	}
+}
+
+func (g *game) clone() *game {
+	c := *g
+	c.bikes = append([]bike{}, g.bikes...)
+	return &c
+}
+
+func (g *game) length() int {
+	return len(g.lanes[0])
+}
+
+// Everything after the end of the bridge is a road.
+func (g *game) isHole(lane, x int) bool {
+	if x >= g.length() {
+		return false
+	}
+	return g.lanes[lane][x] == '0'
+}
+
+func (g *game) alive() int {
+	alive := 0
+	for _, b := range g.bikes {
+		if b.alive {
+			alive++
+		}
+	}
+	return alive
+}
+
+// How an op changes the bikes before they move forward by speed.
+type opRule struct {
+	speedDelta int
+	laneDelta  int
+	jump       bool
+}
+
+var opRules = map[string]opRule{
+	"SPEED": {speedDelta: 1},
+	"SLOW":  {speedDelta: -1},
+	"JUMP":  {jump: true},
+	"WAIT":  {},
+	"UP":    {laneDelta: -1},
+	"DOWN":  {laneDelta: 1},
+}
+
+// apply moves all active bikes according to op. It returns error only if op is not a valid command.
+func (g *game) apply(op string) error {
+	rule, ok := opRules[op]
+	if !ok {
+		return fmt.Errorf("unknown command %q", op)
+	}
+
+	g.speed += rule.speedDelta
+	if g.speed < 0 {
+		g.speed = 0
+	}
+
+	// If any of the bikes can't change the lane, none of them does.
+	for _, b := range g.bikes {
+		if b.alive && (b.lane+rule.laneDelta < 0 || b.lane+rule.laneDelta > 3) {
+			rule.laneDelta = 0
+		}
+	}
+
+	for i, b := range g.bikes {
+		if b.alive {
+			g.bikes[i] = g.move(b, rule)
+		}
+	}
+	g.turns++
+	return nil
+}
+
+func (g *game) move(b bike, rule opRule) bike {
+	newLane := b.lane + rule.laneDelta
+	if !rule.jump {
+		for x := b.x + 1; x < b.x+g.speed; x++ {
+			if g.isHole(b.lane, x) || g.isHole(newLane, x) {
+				b.alive = false
+			}
+		}
+	}
+	if g.isHole(newLane, b.x+g.speed) {
+		b.alive = false
+	}
+
+	b.x += g.speed
+	b.lane = newLane
+	return b
+}
+
+// result returns true if the game is over. Won is meaningful only then.
+func (g *game) result() (over bool, won bool, reason string) {
+	if alive := g.alive(); alive < g.bikesToSurvive {
+		return true, false, fmt.Sprintf("only %d bike(s) left, %d required", alive, g.bikesToSurvive)
+	}
+
+	// The last cell is length-1, so a bike at length is over the bridge. The solver's isFinish is the same.
+	crossed := true
+	for _, b := range g.bikes {
+		if b.alive && b.x < g.length() {
+			crossed = false
+		}
+	}
+	if crossed {
+		return true, true, ""
+	}
+
+	if g.turns >= maxTurns {
+		return true, false, fmt.Sprintf("bridge not crossed in %d turns", maxTurns)
+	}
+	return false, false, ""
+}
+
+// turnInput is what the solver reads at the beginning of every turn.
+func (g *game) turnInput() string {
+	lines := []string{fmt.Sprintf("%d", g.speed)}
+	for _, b := range g.bikes {
+		active := 0
+		if b.alive {
+			active = 1
+		}
+		lines = append(lines, fmt.Sprintf("%d %d %d", b.x, b.lane, active))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -1,26 +1,77 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math"
 	"os"
 	"strings"
 )
 
+// Leveled logging. CodinGame takes a single file, so this block is copied as is into every solver.
+//
+// Only errors are logged by default, so nothing floods stderr on submission. Run with CG_LOG=info or CG_LOG=debug
+// locally, or bump logDefault for the CodinGame IDE.
+type logLevel int
+
+const (
+	lvlError logLevel = iota
+	lvlInfo
+	lvlDebug
+
+	logDefault = lvlError
+)
+
+var (
+	logLvl = parseLogLevel(os.Getenv("CG_LOG"))
+	// Every line is prefixed with it, solvers bump it every turn.
+	logTurn = 0
+)
+
+func parseLogLevel(lvl string) logLevel {
+	switch lvl {
+	case "error":
+		return lvlError
+	case "info":
+		return lvlInfo
+	case "debug":
+		return lvlDebug
+	}
+	return logDefault
+}
+
+func logf(lvl logLevel, format string, a ...interface{}) {
+	if lvl > logLvl {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%03d] %s\n", logTurn, fmt.Sprintf(format, a...))
+}
+
 type Dir int
 
-func (d Dir) Go() {
-	if d == RIGHT {
-		fmt.Println("RIGHT")
-	} else if d == DOWN {
-		fmt.Println("DOWN")
-	} else if d == LEFT {
-		fmt.Println("LEFT")
-	} else if d == UP {
-		fmt.Println("UP")
-	} else {
-		fmt.Fprintln(os.Stderr, "Error")
+func (d Dir) String() string {
+	switch d {
+	case RIGHT:
+		return "RIGHT"
+	case DOWN:
+		return "DOWN"
+	case LEFT:
+		return "LEFT"
+	case UP:
+		return "UP"
+	}
+	return "NONE"
+}
+
+// Go prints the move command. Anything but RIGHT, DOWN, LEFT or UP would be an invalid command, so it is refused:
+// nothing is printed and false is returned.
+func (d Dir) Go() bool {
+	if d < RIGHT || d >= NONE {
+		logf(lvlError, "Refusing to print %d, it is not a move", int(d))
+		return false
 	}
+	fmt.Println(d)
+	return true
 }
 
 func (d Dir) Next() Dir {
@@ -33,7 +84,7 @@ func (d Dir) Next() Dir {
 	} else if d == UP {
 		return RIGHT
 	} else {
-		fmt.Fprintln(os.Stderr, "Error")
+		logf(lvlError, "Error")
 		return NONE
 	}
 }
@@ -67,469 +118,748 @@ type pos struct {
 	y int // col addr
 }
 
-type field struct {
-	availableDirs      map[Dir]*edge
-	availableDirsOrder []Dir
-
-	isJunction    bool
-	isStartPoint  bool
-	isControlRoom bool
+func (p pos) move(dir Dir) pos {
+	switch dir {
+	case RIGHT:
+		return pos{x: p.x, y: p.y + 1}
+	case DOWN:
+		return pos{x: p.x + 1, y: p.y}
+	case LEFT:
+		return pos{x: p.x, y: p.y - 1}
+	case UP:
+		return pos{x: p.x - 1, y: p.y}
+	}
+	return p
+}
 
-	minDist int
+// mazeMap is what Kirk knows about the maze: a grid of fields as scanned so far. It is only about the maze, how it is
+// explored is kept elsewhere.
+type mazeMap struct {
+	rows int
+	cols int
+	// Raw fields: '#' wall, '.' hollow, 'T' start, 'C' control room, '?' not scanned yet.
+	cells [][]byte
+
+	start        pos
+	control      pos
+	controlFound bool
 }
 
-func newField() *field {
-	return &field{
-		minDist: math.MaxInt32,
+func newMazeMap(rows, cols int, start pos) *mazeMap {
+	m := &mazeMap{rows: rows, cols: cols, start: start}
+	for i := 0; i < rows; i++ {
+		m.cells = append(m.cells, []byte(strings.Repeat("?", cols)))
 	}
+	return m
 }
 
-// For not visited path -> mark = 0.
-func (f *field) getFewestMarkDir(r *runner, previousDir Dir) Dir {
-	if len(f.availableDirsOrder) == 1 {
-		return f.availableDirsOrder[0]
+// update replaces i-th row with the one from the input.
+func (m *mazeMap) update(i int, row string) {
+	copy(m.cells[i], row)
+	if j := strings.IndexByte(row, 'C'); j >= 0 {
+		m.control = pos{x: i, y: j}
+		m.controlFound = true
 	}
+}
 
-	minMark := math.MaxInt32
-	minDir := previousDir.Opposite()
-	for _, dir := range f.availableDirsOrder {
-		if previousDir != NONE && dir == previousDir.Opposite() {
-			continue
-		}
-		path := f.availableDirs[dir]
-
-		pathMinMark := 0
-		if path != nil {
-			// Erase the path marks if the adjacent field distance is NOT within 1 distance to us!
-			//adjacentField := r.whatIsIn(r.kirkPos, dir, 1)
-			//if adjacentField != nil {
-			//	// Extension nr 5.
-			//	if f.availableDirs[dir].marks != 0 &&
-			//		adjacentField.minDist != math.MaxInt32 && adjacentField.minDist - f.minDist > 1 {
-			//		fmt.Fprintln(os.Stderr,
-			//			fmt.Sprintf("Erasing path in dir %v, since found this path to be worse (adj dist: %d, my dist: %d)", dir, adjacentField.minDist, f.minDist))
-			//		f.availableDirs[dir].marks -=1
-			//		path.distance = f.minDist
-			//	}
-			//}
-
-			pathMinMark = path.marks
-		}
-
-		if pathMinMark < minMark {
-			minMark = pathMinMark
-			minDir = dir
-		}
-	}
+func (m *mazeMap) isInside(p pos) bool {
+	return p.x >= 0 && p.x < m.rows && p.y >= 0 && p.y < m.cols
+}
 
-	if previousDir.Opposite() != NONE && minMark == 2 {
-		return previousDir.Opposite()
+// at returns the raw field, everything outside of the maze is a wall.
+func (m *mazeMap) at(p pos) byte {
+	if !m.isInside(p) {
+		return '#'
 	}
-
-	return minDir
+	return m.cells[p.x][p.y]
 }
 
-// For not visited path, we are assuming it is exceeding.
-func (f *field) getFewestMarkDirNotExceedingAlarmRound(previousDir Dir, alarmRounds int) Dir {
-	if len(f.availableDirsOrder) == 1 {
-		return f.availableDirsOrder[0]
-	}
+func (m *mazeMap) isUnknown(p pos) bool {
+	return m.at(p) == '?'
+}
 
-	minMark := math.MaxInt32
-	minDir := previousDir.Opposite()
-	for _, dir := range f.availableDirsOrder {
-		if previousDir != NONE && dir == previousDir.Opposite() {
-			continue
-		}
-		path := f.availableDirs[dir]
-		pathMinMark := 0
-		if path != nil {
-			if path.distance >= alarmRounds {
-				continue
-			}
-			pathMinMark = path.marks
-		}
+// isOpen returns true for known fields Kirk can stand on, the control room included.
+func (m *mazeMap) isOpen(p pos) bool {
+	c := m.at(p)
+	return c == '.' || c == 'T' || c == 'C'
+}
 
-		if pathMinMark < minMark {
-			minMark = pathMinMark
-			minDir = dir
+// neighbours returns directions to adjacent fields which are passable, in RIGHT, DOWN, LEFT, UP order.
+func (m *mazeMap) neighbours(p pos, passable func(p pos) bool) []Dir {
+	var dirs []Dir
+	for dir := RIGHT; dir < NONE; dir++ {
+		if passable(p.move(dir)) {
+			dirs = append(dirs, dir)
 		}
 	}
-
-	return minDir
+	return dirs
 }
 
-// Look by adjacent fields not path distance(!)
-func (f *field) getLowestDistanceDir(r *runner, previousDir Dir) Dir {
-	lowestDist := math.MaxInt32
-	minDir := previousDir.Opposite()
-	for _, dir := range f.availableDirsOrder {
-		if dir == previousDir.Opposite() {
-			continue
-		}
-
-		adjacentField := r.whatIsIn(r.kirkPos, dir, 1)
-		minDist := math.MaxInt32
-		if adjacentField != nil {
-			minDist = adjacentField.minDist
-		}
-
-		if minDist < lowestDist {
-			lowestDist = minDist
-			minDir = dir
+// Frontier is a known field next to a not yet scanned one.
+func (m *mazeMap) isFrontier(p pos) bool {
+	for dir := RIGHT; dir < NONE; dir++ {
+		if m.isUnknown(p.move(dir)) {
+			return true
 		}
 	}
-
-	return minDir
+	return false
 }
 
-func (f *field) getLowestDistanceControlDir(previousDir Dir) Dir {
-	lowestDist := math.MaxInt32
-	minDir := previousDir.Opposite()
-	for _, dir := range f.availableDirsOrder {
-		if dir == previousDir.Opposite() {
-			continue
+// distances returns distances from the given field to every field reachable through passable ones (-1 if not
+// reachable) and the direction in which each field was entered, so the path can be walked back.
+func (m *mazeMap) distances(from pos, passable func(p pos) bool) (dist [][]int, via [][]Dir) {
+	dist = make([][]int, m.rows)
+	via = make([][]Dir, m.rows)
+	for i := range dist {
+		dist[i] = make([]int, m.cols)
+		via[i] = make([]Dir, m.cols)
+		for j := range dist[i] {
+			dist[i][j] = -1
+			via[i][j] = NONE
 		}
+	}
 
-		path := f.availableDirs[dir]
-		pathMinDist := math.MaxInt32
-		if path != nil {
-			pathMinDist = path.controlRoomDistance
+	dist[from.x][from.y] = 0
+	queue := []pos{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, dir := range m.neighbours(p, passable) {
+			n := p.move(dir)
+			if dist[n.x][n.y] >= 0 {
+				continue
+			}
+			dist[n.x][n.y] = dist[p.x][p.y] + 1
+			via[n.x][n.y] = dir
+			queue = append(queue, n)
 		}
+	}
+	return dist, via
+}
 
-		if pathMinDist < lowestDist {
-			lowestDist = pathMinDist
-			minDir = dir
-		}
+// shortestPath returns moves of the shortest path through known open fields. Nil if there is no such path.
+func (m *mazeMap) shortestPath(from, to pos) []Dir {
+	dist, via := m.distances(from, m.isOpen)
+	if !m.isInside(to) || dist[to.x][to.y] < 0 {
+		return nil
 	}
 
-	return minDir
+	path := make([]Dir, dist[to.x][to.y])
+	for p, i := to, len(path)-1; p != from; i-- {
+		path[i] = via[p.x][p.y]
+		p = p.move(path[i].Opposite())
+	}
+	return path
 }
 
-type edge struct {
-	marks               int
-	distance            int
-	localDistance       int
-	controlRoomDistance int
-	previousDir         Dir
+// firstDir walks the distances path from `to` back to `from` and returns its first move. NONE if there is no path.
+func firstDir(from, to pos, via [][]Dir) Dir {
+	dir := NONE
+	for p := to; p != from; {
+		dir = via[p.x][p.y]
+		if dir == NONE {
+			return NONE
+		}
+		p = p.move(dir.Opposite())
+	}
+	return dir
 }
 
-func newEdge() *edge {
-	return &edge{
-		controlRoomDistance: math.MaxInt32,
+// String returns the map in the same format as the input rows.
+func (m *mazeMap) String() string {
+	rows := make([]string, 0, m.rows)
+	for _, row := range m.cells {
+		rows = append(rows, string(row))
 	}
+	return strings.Join(rows, "\n")
 }
 
-type runner struct {
-	maze [][]*field
+// strategy decides where Kirk goes until he enters the control room. Everything after is up to the runner: going back
+// to start by the shortest known way, and keeping enough fuel for the control room and back.
+type strategy interface {
+	// nextDir returns the next move from Kirk's position. Entering the control room sets the alarm, so it should only
+	// be returned once the way back fits in alarm rounds. NONE means there is nothing left to explore.
+	nextDir(r *runner) Dir
+}
 
-	jetPackRounds int
-	rows          int
-	cols          int
-	alarmRounds   int
+// marker is optionally implemented by strategies which mark passages as they walk them, like Trémaux. The runner uses
+// the marks when it has to pick a move instead of the strategy (see safeDir), and traces them.
+type marker interface {
+	// marksOf returns how many times the passage from p in dir was walked.
+	marksOf(p pos, dir Dir) int
+	// traceMarks returns the marks and the distance of every field that has them, as runner.trace prints them.
+	traceMarks(rows, cols int) (marks, dists []string)
+}
 
-	controlRoomPos pos
-	kirkPos        pos
+// newStrategy returns the strategy with the given -strategy flag name.
+func newStrategy(name string, eraseMarks bool) (strategy, error) {
+	switch name {
+	case "tremaux":
+		return newTremaux(eraseMarks), nil
+	case "frontier":
+		return frontier{}, nil
+	case "wall":
+		return &wallFollower{heading: NONE, letGo: map[pos][4]bool{}}, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q, expected tremaux, frontier or wall", name)
 }
 
 // Author: witcher92
 // Inspired by Trémaux's algorithm, but with some extensions.
 //
 // Algo:
-//    x---path-(marks: X)--x
+//    x---passage-(marks: X)--x
 //  field  -  field  -  field
-// (junction)		  (junction)
 // 	  |				  	  |
 //	field				field
 //
+// Every walk through a passage between two adjacent fields marks it.
+//
 //  1. Start with random direction (dir)
-// 	When junction is spotted:
-// 	1. If spotted already visited junction, and your actual path == 1, walk back (and mark path)
-//  2. If it is not the case, choose path with the lowest mark (except yours) and mark your path.
-// 	3. If you found a dead end (your end field == start field) mark your path
+// 	When a field is entered:
+// 	1. If it was already visited, and the passage you came by has 1 mark, walk back (and mark it)
+//  2. If it is not the case, choose passage with the lowest mark (except yours), never the one with 2 marks.
+// 	3. If you found a dead end, walk back.
 //
 // Above algo works perfectly find -> at the ends it always finds the control room. But not always finds the shortest path
 // so extensions are needed:
 //
-//	4. If the field's absolute distance from start point >= alarm round - it is not worth to go there, so find the lowest
-//  mark, excluding not visited paths and paths which exceeds alarm. I called artificial wall.
+//	4. If the field's absolute distance from start point >= alarm round - it is not worth to go further, so walk back.
+//  I called artificial wall.
 //  5. If you spot that some adjacent field has significantly larger distance than yours, decrease mark (but no more than 0) and
-//  and reset distance.
+//  and reset distance. Off by default (-erase-marks), it wins some mazes and loses others.
 //
+// The control room is a wall for the algo. Once Kirk is next to it and the shortest known way back fits in alarm rounds,
+// he enters it.
+
+// tremaux is the exploration bookkeeping of the algo above.
+type tremaux struct {
+	// Marks on passages between adjacent fields.
+	marks map[pos][4]int
+	// Shortest walked distance from start to every visited field.
+	minDist map[pos]int
+	// Enable extension nr 5.
+	eraseMarks bool
+
+	// Walked distance to Kirk's field.
+	distance int
+}
 
-func (r *runner) run() {
-	// First iteration grabs the starting point.
-	fmt.Scan(&r.kirkPos.x, &r.kirkPos.y)
+func newTremaux(eraseMarks bool) *tremaux {
+	return &tremaux{
+		marks:      map[pos][4]int{},
+		minDist:    map[pos]int{},
+		eraseMarks: eraseMarks,
+	}
+}
 
-	r.maze = make([][]*field, r.rows)
-	for i := 0; i < r.rows; i++ {
-		r.maze[i] = make([]*field, r.cols)
-		var row string
-		fmt.Scan(&row)
-		for j, char := range strings.Split(row, "") {
-			r.charToMazeField(i, j, char)
+func (t *tremaux) marksOf(p pos, dir Dir) int {
+	return t.marks[p][dir]
+}
+
+// mark adds delta marks to the passage between p and its neighbour in dir. Marks are the same from both sides.
+func (t *tremaux) mark(p pos, dir Dir, delta int) {
+	m := t.marks[p]
+	m[dir] += delta
+	t.marks[p] = m
+
+	n := p.move(dir)
+	m = t.marks[n]
+	m[dir.Opposite()] += delta
+	t.marks[n] = m
+}
+
+func (t *tremaux) traceMarks(rows, cols int) (marks, dists []string) {
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			p := pos{x: i, y: j}
+			if m, ok := t.marks[p]; ok {
+				marks = append(marks, fmt.Sprintf("%d,%d:%d,%d,%d,%d", i, j, m[RIGHT], m[DOWN], m[LEFT], m[UP]))
+			}
+			if d, ok := t.minDist[p]; ok {
+				dists = append(dists, fmt.Sprintf("%d,%d:%d", i, j, d))
+			}
 		}
 	}
-	r.touchAlarm()
+	return marks, dists
 }
 
-func (r *runner) charToMazeField(i, j int, char string) {
-	if char == "C" {
-		r.controlRoomPos = pos{x: i, y: j}
-		r.maze[i][j] = newField()
-		r.maze[i][j].isControlRoom = true
-		return
+// isVisitedExcept returns true if any passage of p except the one in dir was walked already.
+func (t *tremaux) isVisitedExcept(p pos, dir Dir) bool {
+	for d, marks := range t.marks[p] {
+		if Dir(d) != dir && marks > 0 {
+			return true
+		}
 	}
+	return false
+}
 
-	if char == "." {
-		r.maze[i][j] = newField()
-		return
+// visit records walked distance to p and returns the shortest one known. Improved is true if the walked one is the
+// shortest, so it is either the first visit or a shorter way to p was found.
+func (t *tremaux) visit(p pos, distance int) (minDist int, improved bool) {
+	if d, ok := t.minDist[p]; ok && d <= distance {
+		return d, false
 	}
+	t.minDist[p] = distance
+	return distance, true
+}
 
-	if char == "T" {
-		r.maze[i][j] = newField()
-		r.maze[i][j].isStartPoint = true
+func (t *tremaux) nextDir(r *runner) Dir {
+	if r.previousDir != NONE {
+		// Mark the passage Kirk just came by.
+		t.mark(r.kirkPos.move(r.previousDir.Opposite()), r.previousDir, 1)
+		t.distance++
+	}
+	if r.kirkPos == r.known.start {
+		t.distance = 0
 	}
+	improved := false
+	t.distance, improved = t.visit(r.kirkPos, t.distance)
+
+	if controlRoomDir := r.controlRoomDir(); controlRoomDir != NONE {
+		// We could go there and set alarm, but let's check if we have enough way home. Walked distance is not
+		// necessarily the shortest one, so look for the shortest known way instead.
+		if back := r.returnDistance(); back >= 0 && back <= r.alarmRounds {
+			// We are ok! Let's set alarm and let's go back.
+			return controlRoomDir
+		}
+		logf(lvlError, "Control room is nearby, but the shortest known way back is %d (walked distance %d). Alarm: %d",
+			r.returnDistance(), t.distance, r.alarmRounds)
+	}
+
+	if r.kirkPos == r.known.start && r.previousDir != NONE && t.isWalkedThrough(r) {
+		// Trémaux's walk ends back at start with all its passages walked both ways.
+		logf(lvlInfo, "Back at start with every passage marked twice, nothing left to explore")
+		return NONE
+	}
+
+	dir := t.dir(r, improved)
+	logf(lvlDebug, "Field %v, dist: %d, marks: %v. Dir chosen: %v. Prev dir: %v",
+		r.kirkPos, t.distance, t.marks[r.kirkPos], dir, r.previousDir)
+	return dir
+}
+
+// isWalkedThrough returns true if every passage from Kirk's field has 2 marks or more.
+func (t *tremaux) isWalkedThrough(r *runner) bool {
+	for _, dir := range r.known.neighbours(r.kirkPos, r.isWalkable) {
+		if t.marksOf(r.kirkPos, dir) < 2 {
+			return false
+		}
+	}
+	return true
 }
 
-func (r *runner) touchAlarm() {
-	var currentPath *edge
-	for {
-		currentField := r.maze[r.kirkPos.x][r.kirkPos.y]
+// dir chooses the next passage. Improved is true if Kirk just got to his field by the shortest way so far.
+func (t *tremaux) dir(r *runner, improved bool) Dir {
+	dirs := r.known.neighbours(r.kirkPos, r.isWalkable)
+	if len(dirs) == 0 {
+		return NONE
+	}
+	if len(dirs) == 1 {
+		// Dead end (or start of one).
+		return dirs[0]
+	}
 
-		// Process neighbours if needed. Also grab if we are nearby control ROOM!
-		isAlreadyMarked := true
-		controlRoomDir := NONE
-		if len(currentField.availableDirs) == 0 {
-			isAlreadyMarked = false
-			controlRoomDir = r.processAvailablePaths(currentField)
+	back := r.previousDir.Opposite()
+	if back != NONE {
+		if t.distance >= r.alarmRounds {
+			// Stop searching - not worth it. Extension nr 4.
+			logf(lvlDebug, "Putting artifical wall! Distance is too long.")
+			return back
 		}
 
-		// Fill previousDir and inc local distance when currentPath is present.
-		previousDir := NONE
-		distance := 0
-		if currentPath != nil {
-			currentPath.distance++
-			currentPath.localDistance++
-			distance = currentPath.distance
-			previousDir = currentPath.previousDir
+		if t.eraseMarks {
+			if dir := t.erasedDir(r, dirs); dir != NONE {
+				return dir
+			}
 		}
 
-		if currentField.isStartPoint {
-			distance = 0
+		// With extension nr 5 a shorter way to a visited field is worth following further, since distances behind
+		// it get shorter too.
+		if t.isVisitedExcept(r.kirkPos, back) && t.marksOf(r.kirkPos, back) == 1 && !(t.eraseMarks && improved) {
+			// Part of Trémaux's algo: walking back if we came to a visited field through a new passage.
+			return back
 		}
+	}
 
-		if distance < currentField.minDist {
-			currentField.minDist = distance
-		} else {
-			currentPath.distance = distance
+	minMark := math.MaxInt32
+	minDir := back
+	for _, dir := range dirs {
+		if dir == back {
+			continue
 		}
 
-		if controlRoomDir != NONE {
-			// We could go there and set alarm, but let's check if we have enough way home.
-			// NOTE: It won't happen in newest algo.
-			if distance <= r.alarmRounds {
-				// We are ok! Let's set alarm and let's go back.
-				if currentPath == nil {
-					currentPath = newEdge()
-				}
-				currentField.availableDirs[currentPath.previousDir.Opposite()] = currentPath
-				r.setAlarmAndGoBack(controlRoomDir)
-			} else {
-				fmt.Fprintln(os.Stderr,
-					fmt.Sprintf("Control room is nearby, but we have too long distance %d to go. Alarm: %d",
-						currentPath.distance, r.alarmRounds))
-				currentPath.controlRoomDistance = 1 - (currentPath.localDistance + 1)
-			}
+		marks := t.marksOf(r.kirkPos, dir)
+		if marks < minMark {
+			minMark = marks
+			minDir = dir
 		}
+	}
 
-		dir := NONE
-		if currentField.minDist >= r.alarmRounds {
-			// Stop searching - not worth it. Extension nr 4.
-			fmt.Fprintln(os.Stderr, "Putting artifical wall! Distance is too long.")
-			dir = currentField.getFewestMarkDirNotExceedingAlarmRound(previousDir, r.alarmRounds)
-		} else {
-			// Find a direction with the fewest marks. Excluding the previousDir if not NONE.
-			dir = currentField.getFewestMarkDir(r, previousDir)
+	if back != NONE && minMark >= 2 {
+		return back
+	}
+	return minDir
+}
+
+// erasedDir is extension nr 5: if an adjacent visited field has significantly larger distance than ours, we found a
+// shorter way to it. It matters only if the field was behind the artificial wall (extension nr 4) and through us it is
+// not anymore. Erase one mark of the passage there and go there right away, so the walk does not count and whatever
+// was cut off behind the wall can be explored. NONE if there is no such field.
+//
+// Only fields behind the wall count, not every field with distance larger than ours + 1: erasing passages to all of
+// them makes Kirk walk large parts of the maze again and again. Short of the wall Kirk follows a shorter way anyway,
+// since he keeps going from a field he reached by a shorter way (see dir).
+//
+// It does not break Trémaux's termination: every erase is followed by a walk which makes the adjacent field distance
+// ours + 1, so the sum of distances of visited fields drops with every erase. It can't drop forever, so after the last
+// erase it is plain Trémaux again.
+func (t *tremaux) erasedDir(r *runner, dirs []Dir) Dir {
+	if t.distance+1 >= r.alarmRounds {
+		return NONE
+	}
+
+	for _, dir := range dirs {
+		adjacentDist, ok := t.minDist[r.kirkPos.move(dir)]
+		if !ok || adjacentDist < r.alarmRounds || t.marksOf(r.kirkPos, dir) == 0 {
+			continue
 		}
 
-		fmt.Fprintln(os.Stderr,
-			fmt.Sprintf("Dirs found: %v. Field was already processed? %v. \n FieldDist:%v Dir chosen: %v. Prev dir: %v. Curr path: %+v",
-				r.printDirs(currentField), isAlreadyMarked, currentField.minDist, dir, previousDir, currentPath))
-
-		if currentField.isJunction {
-			// It's junction, so  we need to increase the mark and create a new path if it's nil.
-			// Part of Trémaux's algo (walking back if mark == 1 and junction is marked)
-			if currentPath != nil {
-				if isAlreadyMarked && currentPath.marks == 1 &&
-					currentField.availableDirs[currentPath.previousDir.Opposite()] == nil {
-					// Walk back!
-					dir = currentPath.previousDir.Opposite()
-				}
-
-				currentPath.marks++
-				if currentField.availableDirs[currentPath.previousDir.Opposite()] != nil {
-					// If it is already set, it means we had been there (e.g it was dead end)
-					currentPath.marks = 2
-				}
-
-				currentField.availableDirs[currentPath.previousDir.Opposite()] = currentPath
-			}
+		logf(lvlDebug, "Erasing passage in dir %v, since found this path to be worse (adj dist: %d, my dist: %d)",
+			dir, adjacentDist, t.distance)
+		t.mark(r.kirkPos, dir, -1)
+		return dir
+	}
+	return NONE
+}
 
-			if currentField.availableDirs[dir] == nil {
-				currentField.availableDirs[dir] = newEdge()
-			}
+// Frontier exploration. Unlike Trémaux it does not care about passages and marks, it uses everything scanned so far:
+//
+//  1. If the control room is known and the shortest known way from it back to start fits in alarm rounds, go to the
+//     control room by the shortest known path.
+//  2. Otherwise go to the nearest known field with a not yet scanned neighbour (frontier). The control room is a wall
+//     until then, since entering it starts the alarm.
+type frontier struct{}
+
+func (frontier) nextDir(r *runner) Dir {
+	if dir := r.wayToControlRoom(); dir != NONE {
+		return dir
+	}
 
-			currentField.availableDirs[dir].distance = currentField.minDist
-			currentField.availableDirs[dir].localDistance = 0
-		} else {
-			if currentPath == nil {
-				// Just the first iteration.
-				currentPath = newEdge()
+	dist, via := r.known.distances(r.kirkPos, r.isWalkable)
+	nearest := pos{x: -1}
+	for i := range dist {
+		for j, d := range dist[i] {
+			p := pos{x: i, y: j}
+			if d < 0 || !r.known.isFrontier(p) {
+				continue
 			}
+			if nearest.x < 0 || d < dist[nearest.x][nearest.y] {
+				nearest = p
+			}
+		}
+	}
+	if nearest.x < 0 {
+		logf(lvlInfo, "No frontier left, nothing left to explore")
+		return NONE
+	}
 
-			currentField.availableDirs[currentPath.previousDir.Opposite()] = currentPath
+	logf(lvlDebug, "Nearest frontier %v is %d away", nearest, dist[nearest.x][nearest.y])
+	return firstDir(r.kirkPos, nearest, via)
+}
 
-			// Just path, so if path nil it is the same as currentPath.
-			if currentField.availableDirs[dir] == nil {
-				if currentField.isStartPoint {
-					currentField.availableDirs[dir] = newEdge()
-				} else {
-					currentField.availableDirs[dir] = currentPath
-				}
-			}
+// wallFollower keeps Kirk's right hand on a wall. He goes straight until he hits one, then at every field he tries
+// to turn right, go straight, turn left and go back, in this order. Like frontier, he goes to the control room as soon
+// as the way back fits in alarm rounds.
+//
+// It is a baseline, following a wall only ever explores that wall. If Kirk gets to the same field heading the same
+// way again, he is walking around an island, so he lets go and goes straight to another wall. Letting go at the same
+// field heading the same way again means the walk repeats itself, there is nothing left to explore.
+type wallFollower struct {
+	onWall bool
+	// Heading is the direction Kirk is going, NONE until the first move.
+	heading Dir
+	// Headings Kirk had on every field since he holds the wall, in RIGHT, DOWN, LEFT, UP order.
+	seen map[pos][4]bool
+	// Fields and headings where Kirk let go of the wall, the same way.
+	letGo map[pos][4]bool
+}
+
+func (w *wallFollower) nextDir(r *runner) Dir {
+	if dir := r.wayToControlRoom(); dir != NONE {
+		return dir
+	}
+
+	if r.previousDir != NONE {
+		w.heading = r.previousDir
+	}
+	if w.heading == NONE {
+		dirs := r.known.neighbours(r.kirkPos, r.isWalkable)
+		if len(dirs) == 0 {
+			return NONE
 		}
+		w.heading = dirs[0]
+	}
 
-		// path is now our currentPath!
-		currentPath = currentField.availableDirs[dir]
-		currentPath.previousDir = dir
+	if !w.onWall {
+		if r.isWalkable(r.kirkPos.move(w.heading)) {
+			return w.heading
+		}
+		// Hit the wall, turn left so it is on the right.
+		logf(lvlDebug, "Hit a wall at %v heading %v", r.kirkPos, w.heading)
+		w.onWall = true
+		w.seen = map[pos][4]bool{}
+		w.heading = w.heading.Opposite().Next()
+	}
 
-		dir.Go()
-		r.jetPackRounds--
-		r.updateMazeFromInput()
+	seen := w.seen[r.kirkPos]
+	if seen[w.heading] {
+		letGo := w.letGo[r.kirkPos]
+		if letGo[w.heading] && r.returnDistance() >= 0 {
+			logf(lvlInfo, "Let go of the wall at %v heading %v already, nothing left to explore", r.kirkPos, w.heading)
+			return NONE
+		}
+		letGo[w.heading] = true
+		w.letGo[r.kirkPos] = letGo
+
+		logf(lvlDebug, "Been at %v heading %v already, letting go of the wall", r.kirkPos, w.heading)
+		w.onWall = false
+		return w.nextDir(r)
 	}
-}
+	seen[w.heading] = true
+	w.seen[r.kirkPos] = seen
 
-func (r *runner) printDirs(currentField *field) string {
-	var dirs []string
-	for dir, path := range currentField.availableDirs {
-		dirs = append(dirs, fmt.Sprintf("%d: %v", dir, path))
+	right := w.heading.Next()
+	for _, dir := range []Dir{right, w.heading, right.Opposite(), w.heading.Opposite()} {
+		if r.isWalkable(r.kirkPos.move(dir)) {
+			return dir
+		}
 	}
-	return strings.Join(dirs, ",")
+	return NONE
 }
-func (r *runner) returnToControlRoom() Dir {
-	previousDir := NONE
-	for {
-		currentField := r.maze[r.kirkPos.x][r.kirkPos.y]
 
-		dir := currentField.getLowestDistanceControlDir(previousDir)
+type runner struct {
+	known    *mazeMap
+	strategy strategy
 
-		fmt.Fprintln(os.Stderr,
-			fmt.Sprintf("CTRL! Dirs found: %v\nDir chosen: %v. Prev dir: %v ctrl dist: %d",
-				currentField.availableDirs, dir, previousDir, currentField.availableDirs[dir].controlRoomDistance))
+	jetPackRounds int
+	rows          int
+	cols          int
+	alarmRounds   int
 
-		if currentField.availableDirs[dir].controlRoomDistance <= 2 {
-			// We are close, in one move we will be close. Return to normal flow.
-			return dir
+	kirkPos pos
+	// Direction of the last move, NONE before the first one.
+	previousDir Dir
+}
+
+// run lets the strategy lead Kirk to the control room, unless the strategy has nothing left to explore or the fuel
+// runs short, then he goes there right away. If the strategy is done before any way back is known, frontier
+// exploration takes over. From the control room he goes back to start by the shortest known way, it was checked to
+// fit the alarm before he entered.
+func (r *runner) run() {
+	// First iteration grabs the starting point.
+	fmt.Scan(&r.kirkPos.x, &r.kirkPos.y)
+
+	r.known = newMazeMap(r.rows, r.cols, r.kirkPos)
+	r.previousDir = NONE
+	r.readRows()
+
+	for !r.known.controlFound || r.kirkPos != r.known.control {
+		dir := r.strategy.nextDir(r)
+		if dir == NONE && r.returnDistance() >= 0 {
+			// Exploring more won't find a shorter way back, so no point to burn the fuel.
+			r.commitToControlRoom()
+			break
 		}
+		if dir == NONE {
+			if _, ok := r.strategy.(frontier); !ok {
+				// E.g. Trémaux does not go behind its artificial wall, but the way may be there.
+				logf(lvlInfo, "Nothing left to explore, but no way back from the control room is known, "+
+					"going to the nearest unscanned fields instead")
+				r.strategy = frontier{}
+				continue
+			}
+			logf(lvlError, "Everything reachable is scanned and there is no way back from the control room, giving up")
+			return
+		}
+		if !r.canAfford(r.kirkPos.move(dir)) {
+			r.commitToControlRoom()
+			break
+		}
+		if r.previousDir = r.move(dir); r.previousDir == NONE {
+			return
+		}
+	}
 
-		dir.Go()
-		previousDir = dir
-		r.jetPackRounds--
-		r.updateMazeFromInput()
+	logf(lvlInfo, "Alarm set, going back %d to start", r.returnDistance())
+	for r.kirkPos != r.known.start {
+		if r.move(r.homeDir()) == NONE {
+			return
+		}
 	}
 }
 
-func (r *runner) setAlarmAndGoBack(controllerRoomDir Dir) {
-	controllerRoomDir.Go()
-	r.jetPackRounds--
-	r.updateMazeFromInput()
+func (r *runner) readRows() {
+	for i := 0; i < r.rows; i++ {
+		var row string
+		fmt.Scan(&row)
+		r.known.update(i, row)
+	}
+}
 
-	currentField := r.maze[r.kirkPos.x][r.kirkPos.y]
+// isWalkable is where strategies can go, the control room is a wall until the alarm is set.
+func (r *runner) isWalkable(p pos) bool {
+	return r.known.isOpen(p) && !(r.known.controlFound && p == r.known.control)
+}
 
-	if len(currentField.availableDirs) == 0 {
-		_ = r.processAvailablePaths(currentField)
+// controlRoomDir returns direction to the control room if it is next to Kirk, NONE otherwise.
+func (r *runner) controlRoomDir() Dir {
+	for _, dir := range r.known.neighbours(r.kirkPos, r.known.isOpen) {
+		if r.kirkPos.move(dir) == r.known.control && r.known.controlFound {
+			logf(lvlInfo, "Found!")
+			return dir
+		}
 	}
+	return NONE
+}
 
-	previousDir := controllerRoomDir
-	for {
-		currentField = r.maze[r.kirkPos.x][r.kirkPos.y]
-
-		dir := currentField.getLowestDistanceDir(r, previousDir)
+// wayToControlRoom is the first move of the shortest known path to the control room, if the shortest known way back
+// from it fits in alarm rounds. NONE otherwise.
+func (r *runner) wayToControlRoom() Dir {
+	d := r.returnDistance()
+	if d < 0 || d > r.alarmRounds {
+		return NONE
+	}
 
-		fmt.Fprintln(os.Stderr,
-			fmt.Sprintf("Dirs found: %v\nDir chosen: %v. Prev dir: %v",
-				currentField.availableDirs, dir, previousDir))
+	logf(lvlDebug, "Heading to the control room, way back is %d, alarm: %d", d, r.alarmRounds)
+	_, via := r.known.distances(r.kirkPos, r.known.isOpen)
+	return firstDir(r.kirkPos, r.known.control, via)
+}
 
-		dir.Go()
-		previousDir = dir
-		r.jetPackRounds--
-		r.updateMazeFromInput()
+// move makes Kirk go in dir and reads what he sees after the move. It returns the direction he actually went, see
+// safeDir. NONE if there is no valid move at all, then nothing is printed and the game can't go on.
+func (r *runner) move(dir Dir) Dir {
+	dir = r.safeDir(dir)
+	r.trace(dir)
+	if !dir.Go() {
+		return NONE
 	}
+	r.jetPackRounds--
+	r.updateMazeFromInput()
+	return dir
 }
 
-func (r *runner) processAvailablePaths(currentField *field) (controlRoomDir Dir) {
-	// Let's check what is around!
-	dir := UP
-	controlRoomDir = NONE
-	currentField.availableDirs = map[Dir]*edge{}
-	for i := 0; i < 4; i++ {
-		dir = dir.Next()
-		adjacentField := r.whatIsIn(r.kirkPos, dir, 1)
-		if adjacentField == nil {
-			continue
-		}
+// safeDir validates dir against the known map. The referee ends the game on anything but a move to an open field, so
+// instead of NONE, a wall or the maze edge, Kirk goes to an open neighbour: the least marked one (for Trémaux) which
+// is not the control room, or the control room if there is nothing else. If Kirk is walled in, there is no valid move
+// and it returns NONE.
+func (r *runner) safeDir(dir Dir) Dir {
+	next := r.kirkPos.move(dir)
+	var reason string
+	switch {
+	case dir < RIGHT || dir >= NONE:
+		reason = "no direction"
+	case !r.known.isInside(next):
+		reason = "outside of the maze"
+	case !r.known.isOpen(next):
+		reason = fmt.Sprintf("%q field", r.known.at(next))
+	default:
+		return dir
+	}
 
-		if adjacentField.isControlRoom {
-			controlRoomDir = dir
-			fmt.Fprintln(os.Stderr, "Found!")
-			// DO not set the control room as available path (:
-			continue
-		}
+	dirs := r.known.neighbours(r.kirkPos, r.isWalkable)
+	if len(dirs) == 0 {
+		dirs = r.known.neighbours(r.kirkPos, r.known.isOpen)
+	}
+	if len(dirs) == 0 {
+		logf(lvlError, "%v from %v leads to %s and there is no open neighbour, giving up", dir, r.kirkPos, reason)
+		return NONE
+	}
 
-		currentField.availableDirs[dir] = nil
-		currentField.availableDirsOrder = append(currentField.availableDirsOrder, dir)
+	safe := dirs[0]
+	if m, ok := r.strategy.(marker); ok {
+		for _, d := range dirs[1:] {
+			if m.marksOf(r.kirkPos, d) < m.marksOf(r.kirkPos, safe) {
+				safe = d
+			}
+		}
 	}
+	logf(lvlError, "%v from %v leads to %s, going %v instead", dir, r.kirkPos, reason, safe)
+	return safe
+}
 
-	if len(currentField.availableDirs) > 2 {
-		currentField.isJunction = true
+// trace logs everything the runner knows before the move as a single line, for the sim trace viewer:
+//
+//	TRACE kirk=<row>,<col> dir=<dir> map=<row>/<row>/... marks=<row>,<col>:<R>,<D>,<L>,<U>;... dist=<row>,<col>:<minDist>;...
+//
+// Marks are per passage of the field in RIGHT, DOWN, LEFT, UP order. Fields without marks or distance are skipped,
+// strategies which are not a marker have none.
+func (r *runner) trace(dir Dir) {
+	if logLvl < lvlDebug {
+		return
 	}
 
-	return controlRoomDir
+	var marks, dists []string
+	if m, ok := r.strategy.(marker); ok {
+		marks, dists = m.traceMarks(r.rows, r.cols)
+	}
+	logf(lvlDebug, "TRACE kirk=%d,%d dir=%v map=%s marks=%s dist=%s", r.kirkPos.x, r.kirkPos.y, dir,
+		strings.Replace(r.known.String(), "\n", "/", -1), strings.Join(marks, ";"), strings.Join(dists, ";"))
 }
 
 func (r *runner) updateMazeFromInput() {
 	// Kirk location.
 	fmt.Scan(&r.kirkPos.x, &r.kirkPos.y)
+	logTurn++
 
-	for i := 0; i < r.rows; i++ {
-		var row string
-		fmt.Scan(&row)
-		for j, char := range strings.Split(row, "") {
-			if r.maze[i][j] != nil {
-				continue
-			}
+	r.readRows()
+}
 
-			r.charToMazeField(i, j, char)
-		}
+// canAfford returns false if after moving to next there would not be enough fuel left to get to the control room and
+// back to start by the shortest known ways. Until the control room is known (and reachable) there is nothing to save
+// fuel for, so any move is fine.
+func (r *runner) canAfford(next pos) bool {
+	if !r.known.controlFound {
+		return true
 	}
-}
 
-func (r *runner) whatIsIn(p pos, dir Dir, dist int) *field {
-	if dir == RIGHT {
-		return r.maze[p.x][p.y+dist]
+	dist, _ := r.known.distances(r.known.control, r.known.isOpen)
+	toControl, back := dist[next.x][next.y], dist[r.known.start.x][r.known.start.y]
+	if toControl < 0 || back < 0 {
+		return true
 	}
-	if dir == DOWN {
-		return r.maze[p.x+dist][p.y]
+	return r.jetPackRounds-1 >= toControl+back
+}
+
+// commitToControlRoom stops exploring and goes to the control room by the shortest known way. Used when there is
+// nothing left to explore, or just enough fuel left for that and back.
+func (r *runner) commitToControlRoom() {
+	path := r.known.shortestPath(r.kirkPos, r.known.control)
+	back := r.returnDistance()
+	logf(lvlInfo, "Fuel %d, going %d to the control room and %d back", r.jetPackRounds, len(path), back)
+	if back > r.alarmRounds {
+		logf(lvlError, "Going to the control room, but the shortest known way back %d does not fit the alarm: %d",
+			back, r.alarmRounds)
 	}
-	if dir == LEFT {
-		return r.maze[p.x][p.y-dist]
+
+	for _, dir := range path {
+		r.move(dir)
 	}
-	if dir == UP {
-		return r.maze[p.x-dist][p.y]
+}
+
+// returnDistance is the length of the shortest known path from the control room to start, -1 if there is none.
+func (r *runner) returnDistance() int {
+	if !r.known.controlFound {
+		return -1
 	}
+	dist, _ := r.known.distances(r.known.start, r.known.isOpen)
+	return dist[r.known.control.x][r.known.control.y]
+}
 
-	return nil
+// homeDir is the first move of the shortest known path to start.
+func (r *runner) homeDir() Dir {
+	_, via := r.known.distances(r.kirkPos, r.known.isOpen)
+	return firstDir(r.kirkPos, r.known.start, via)
 }
 
 func main() {
@@ -537,7 +867,17 @@ func main() {
 	// Cols: number of columns.
 	// AlarmRounds: number of rounds between the time the alarm countdown is activated and the time the alarm goes off.
 
-	r := runner{}
+	name := flag.String("strategy", "tremaux", "Exploration strategy: tremaux, frontier (BFS to the nearest unscanned field) or wall (right hand on the wall).")
+	eraseMarks := flag.Bool("erase-marks", false, "Trémaux extension nr 5: walk again passages to fields which turned out to be closer.")
+	flag.Parse()
+
+	s, err := newStrategy(*name, *eraseMarks)
+	if err != nil {
+		logf(lvlError, "%v", err)
+		os.Exit(2)
+	}
+
+	r := runner{strategy: s, jetPackRounds: JETPACK_ROUNDS}
 	fmt.Scan(&r.rows, &r.cols, &r.alarmRounds)
 	r.run()
 }
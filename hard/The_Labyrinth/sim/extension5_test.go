@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Run with 'go test *.go' from this directory, there is no go.mod. The solver is built from ../labirynth.go.
+
+// tremauxRoute returns the way back from the control room Trémaux found by itself, parsed from the solver's info log
+// (see runner.run in labirynth.go). -1 if it found none and frontier exploration had to take over.
+func tremauxRoute(stderr string) int {
+	if strings.Contains(stderr, "going to the nearest unscanned fields") {
+		return -1
+	}
+	m := regexp.MustCompile(`Alarm set, going back (\d+) to start`).FindStringSubmatch(stderr)
+	if m == nil {
+		return -1
+	}
+	route, _ := strconv.Atoi(m[1])
+	return route
+}
+
+// TestExtension5 checks that the mazes in ../fixtures/extension5 are still the ones extension nr 5 is for: the route
+// Trémaux finds with -erase-marks is shorter. Plain Trémaux walks through everything it can reach without finding
+// any way to the control room, so the runner has to hand over to frontier exploration, which finds it. Both win, the
+// mazes have no slack so the final way back is the shortest one either way.
+func TestExtension5(t *testing.T) {
+	solver := filepath.Join(t.TempDir(), "labyrinth")
+	if out, err := exec.Command("go", "build", "-o", solver, "../labirynth.go").CombinedOutput(); err != nil {
+		t.Fatalf("building the solver: %v\n%s", err, out)
+	}
+
+	fixtures, err := loadFixtures("../fixtures/extension5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures in ../fixtures/extension5")
+	}
+
+	for _, fix := range fixtures {
+		routes := map[bool]int{}
+		for _, eraseMarks := range []bool{false, true} {
+			var args []string
+			if eraseMarks {
+				args = []string{"-erase-marks"}
+			}
+
+			var stderr bytes.Buffer
+			ref := referee{
+				solver:           append([]string{solver}, args...),
+				firstTurnTimeout: 1 * time.Second,
+				turnTimeout:      150 * time.Millisecond,
+				env:              []string{"CG_LOG=info"},
+				stderr:           &stderr,
+			}
+			res, err := ref.play(fix.maze)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.won {
+				t.Errorf("%s with %v: expected win, got %v", fix.name, args, res)
+			}
+			routes[eraseMarks] = tremauxRoute(stderr.String())
+		}
+
+		plain, erased := routes[false], routes[true]
+		if erased < 0 || erased > fix.maze.alarmRounds {
+			t.Errorf("%s: with -erase-marks Trémaux found no way back within the alarm %d, got %d", fix.name,
+				fix.maze.alarmRounds, erased)
+		}
+		if plain >= 0 && plain <= erased {
+			t.Errorf("%s: with -erase-marks Trémaux found a way back of %d, without it %d, expected it shorter",
+				fix.name, erased, plain)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fixture is a maze with metadata on top, as '// key: value' lines:
+//
+//	// name: Simple corridor
+//	// expect: win
+//	5 10 10
+//	##########
+//	...
+//
+// Since the metadata are just comments, every fixture is a valid maze for 'sim play' as well.
+type fixture struct {
+	path string
+	name string
+	// Expected outcome, win or loss.
+	expect string
+
+	maze
+}
+
+func loadFixture(path string) (fixture, error) {
+	m, err := loadMaze(path)
+	if err != nil {
+		return fixture{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fixture{}, err
+	}
+	defer f.Close()
+
+	fix := fixture{
+		path:   path,
+		name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		expect: "win",
+		maze:   m,
+	}
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		kv := strings.SplitN(strings.TrimPrefix(line, "//"), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "name":
+			fix.name = value
+		case "expect":
+			if value != "win" && value != "loss" {
+				return fixture{}, fmt.Errorf("%s: expect has to be win or loss, got %q", path, value)
+			}
+			fix.expect = value
+		}
+	}
+	return fix, s.Err()
+}
+
+// loadFixtures loads all *.txt fixtures from dir, sorted by file name.
+func loadFixtures(dir string) ([]fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.txt fixtures in %s", dir)
+	}
+	sort.Strings(paths)
+
+	var fixtures []fixture
+	for _, path := range paths {
+		fix, err := loadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fix)
+	}
+	return fixtures, nil
+}
+
+func (f fixture) isExpected(res result) bool {
+	return res.won == (f.expect == "win")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+type genConfig struct {
+	rows int
+	cols int
+	// Probability of knocking down each inner wall left by the perfect maze, so there are loops. 0 keeps the maze
+	// perfect: exactly one path between any two fields.
+	loops float64
+	// Alarm rounds on top of the shortest way back from the control room.
+	slack int
+	// Shortest way between start and control room is at least that long, if the maze allows.
+	minDistance int
+}
+
+func (c genConfig) validate() error {
+	switch {
+	case c.rows < 5 || c.cols < 5:
+		return fmt.Errorf("maze has to be at least 5x5, got %dx%d", c.rows, c.cols)
+	case c.loops < 0 || c.loops > 1:
+		return fmt.Errorf("loops probability has to be in [0, 1], got %v", c.loops)
+	case c.slack < 0:
+		return fmt.Errorf("negative alarm slack %d", c.slack)
+	}
+	return nil
+}
+
+// randomMaze carves a perfect maze with a randomized depth-first search over fields with odd coordinates (walls are
+// in between), then knocks down some of the remaining inner walls. Start and control room are placed on random
+// fields, alarm rounds are set so Kirk can make it back by the shortest way.
+func randomMaze(c genConfig, rnd *rand.Rand) (maze, error) {
+	if err := c.validate(); err != nil {
+		return maze{}, err
+	}
+
+	grid := make([][]byte, c.rows)
+	for i := range grid {
+		grid[i] = bytes.Repeat([]byte{'#'}, c.cols)
+	}
+
+	isField := func(p pos) bool {
+		return p.row > 0 && p.row < c.rows-1 && p.col > 0 && p.col < c.cols-1 && p.row%2 == 1 && p.col%2 == 1
+	}
+
+	var fields []pos
+	stack := []pos{{row: 1, col: 1}}
+	grid[1][1] = '.'
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+
+		var next []pos
+		for _, d := range moveDeltas {
+			n := pos{row: p.row + 2*d.row, col: p.col + 2*d.col}
+			if isField(n) && grid[n.row][n.col] == '#' {
+				next = append(next, n)
+			}
+		}
+		if len(next) == 0 {
+			fields = append(fields, p)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		n := next[rnd.Intn(len(next))]
+		grid[(p.row+n.row)/2][(p.col+n.col)/2] = '.'
+		grid[n.row][n.col] = '.'
+		stack = append(stack, n)
+	}
+
+	// Inner walls between two fields, either horizontally or vertically.
+	for i := 1; i < c.rows-1; i++ {
+		for j := 1; j < c.cols-1; j++ {
+			between := (i%2 == 1 && j%2 == 0 && j+1 < c.cols-1) || (i%2 == 0 && j%2 == 1 && i+1 < c.rows-1)
+			if between && grid[i][j] == '#' && rnd.Float64() < c.loops {
+				grid[i][j] = '.'
+			}
+		}
+	}
+
+	m := maze{rows: c.rows, cols: c.cols}
+	for _, row := range grid {
+		m.cells = append(m.cells, string(row))
+	}
+	m.start = fields[rnd.Intn(len(fields))]
+
+	// Random field far enough, or the farthest one if none is.
+	var far []pos
+	farthest, farthestDist := m.start, 0
+	dist := m.distances(m.start)
+	for _, f := range fields {
+		d := dist[f]
+		if d >= c.minDistance && f != m.start {
+			far = append(far, f)
+		}
+		if d > farthestDist {
+			farthest, farthestDist = f, d
+		}
+	}
+	m.control = farthest
+	if len(far) > 0 {
+		m.control = far[rnd.Intn(len(far))]
+	}
+
+	m.set(m.start, 'T')
+	m.set(m.control, 'C')
+	m.alarmRounds = m.distance(m.control, m.start) + c.slack
+	if !m.isSolvable() {
+		return maze{}, fmt.Errorf("generated maze is not solvable, way back of %d is too long for the jetpack", m.alarmRounds-c.slack)
+	}
+	return m, nil
+}
+
+func (m *maze) set(p pos, c byte) {
+	row := []byte(m.cells[p.row])
+	row[p.col] = c
+	m.cells[p.row] = string(row)
+}
+
+// writeFixture writes m in the fixture format, with the expected outcome and notes as comments.
+func writeFixture(w io.Writer, name string, m maze, notes ...string) error {
+	expect := "loss"
+	if m.isSolvable() {
+		expect = "win"
+	}
+
+	lines := []string{
+		"// name: " + name,
+		"// expect: " + expect,
+		fmt.Sprintf("// shortest way back: %d", m.distance(m.control, m.start)),
+	}
+	for _, note := range notes {
+		lines = append(lines, "// note: "+note)
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n"+m.String())
+	return err
+}
@@ -0,0 +1,306 @@
+// Command sim is a local referee for The Labyrinth. It runs the solver as a separate process (the same single file
+// which is submitted to CodinGame) and talks to it over stdin/stdout with exactly the same turn protocol: Kirk's
+// position and the maze as scanned so far, 5x5 cells around Kirk on every turn.
+//
+// Build (there is no go.mod, so list the files):
+//
+//	go build -o /tmp/labyrinth ../labirynth.go
+//	go build -o /tmp/labyrinth-sim *.go
+//
+// Usage:
+//
+//	/tmp/labyrinth-sim play -solver /tmp/labyrinth -maze ../fixtures/01_corridor.txt
+//	/tmp/labyrinth-sim suite -solver /tmp/labyrinth -dir ../fixtures
+//	/tmp/labyrinth-sim gen -n 100 -rows 15 -cols 31 -loops 0.1 -slack 5 -out /tmp/mazes
+//	/tmp/labyrinth-sim tournament -solver /tmp/labyrinth -dir ../fixtures -quiet
+//	/tmp/labyrinth-sim trace -solver /tmp/labyrinth -maze ../fixtures/04_loops.txt
+//	CG_LOG=debug /tmp/labyrinth < input.txt 2> run.log; /tmp/labyrinth-sim trace -log run.log -turn 40
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: sim <command> [flags]
+
+Commands:
+  play        Run the solver against a single maze and report win/loss.
+  suite       Run the solver against all fixtures in a directory.
+  gen         Generate random perfect or imperfect mazes with a solvable alarm.
+  tournament  Run every solver strategy against all fixtures in a directory and compare them.
+  trace       Step through a run turn by turn: known map, Trémaux marks and minDist.
+
+Run 'sim <command> -h' for command flags.`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "play":
+		err = play(os.Args[2:])
+	case "suite":
+		err = suite(os.Args[2:])
+	case "gen":
+		err = gen(os.Args[2:])
+	case "tournament":
+		err = tournamentCmd(os.Args[2:])
+	case "trace":
+		err = trace(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sim:", err)
+		os.Exit(1)
+	}
+}
+
+type refereeFlags struct {
+	solver           string
+	firstTurnTimeout time.Duration
+	turnTimeout      time.Duration
+	quiet            bool
+}
+
+func registerRefereeFlags(fs *flag.FlagSet) *refereeFlags {
+	f := &refereeFlags{}
+	fs.StringVar(&f.solver, "solver", "", "Solver binary with optional args, e.g. '/tmp/labyrinth'.")
+	fs.DurationVar(&f.firstTurnTimeout, "first-timeout", 1*time.Second, "Time limit for the first turn.")
+	fs.DurationVar(&f.turnTimeout, "timeout", 150*time.Millisecond, "Time limit for every next turn.")
+	fs.BoolVar(&f.quiet, "quiet", false, "Drop the solver's stderr.")
+	return f
+}
+
+func (f *refereeFlags) referee() (referee, error) {
+	solver := strings.Fields(f.solver)
+	if len(solver) == 0 {
+		return referee{}, fmt.Errorf("-solver is required")
+	}
+
+	var stderr io.Writer = os.Stderr
+	if f.quiet {
+		stderr = ioutil.Discard
+	}
+	return referee{
+		solver:           solver,
+		firstTurnTimeout: f.firstTurnTimeout,
+		turnTimeout:      f.turnTimeout,
+		stderr:           stderr,
+	}, nil
+}
+
+func play(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	mazePath := fs.String("maze", "", "Maze file: 'rows cols alarmRounds' line, then the full maze.")
+	verbose := fs.Bool("v", false, "Print every turn.")
+	_ = fs.Parse(args)
+
+	ref, err := rf.referee()
+	if err != nil {
+		return err
+	}
+	if *verbose {
+		ref.verbose = os.Stdout
+	}
+
+	m, err := loadMaze(*mazePath)
+	if err != nil {
+		return err
+	}
+
+	res, err := ref.play(m)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(res)
+	if !res.won {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func suite(args []string) error {
+	fs := flag.NewFlagSet("suite", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	dir := fs.String("dir", "../fixtures", "Directory with *.txt fixtures.")
+	_ = fs.Parse(args)
+
+	ref, err := rf.referee()
+	if err != nil {
+		return err
+	}
+
+	fixtures, err := loadFixtures(*dir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIXTURE\tEXPECT\tRESULT\t")
+	unexpected := 0
+	for _, fix := range fixtures {
+		res, err := ref.play(fix.maze)
+		if err != nil {
+			return err
+		}
+
+		status := ""
+		if !fix.isExpected(res) {
+			status = "UNEXPECTED"
+			unexpected++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", fix.name, fix.expect, res, status)
+	}
+	_ = w.Flush()
+
+	if unexpected > 0 {
+		return fmt.Errorf("%d of %d fixtures with unexpected result", unexpected, len(fixtures))
+	}
+	fmt.Printf("All %d fixtures as expected.\n", len(fixtures))
+	return nil
+}
+
+func gen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	c := genConfig{}
+	fs.IntVar(&c.rows, "rows", 15, "Maze rows, odd numbers give a maze without double walls.")
+	fs.IntVar(&c.cols, "cols", 31, "Maze columns, odd numbers give a maze without double walls.")
+	fs.Float64Var(&c.loops, "loops", 0, "Probability of knocking down each inner wall, 0 gives a perfect maze.")
+	fs.IntVar(&c.slack, "slack", 0, "Alarm rounds on top of the shortest way back.")
+	fs.IntVar(&c.minDistance, "min-distance", 10, "Shortest way between start and control room, if the maze allows.")
+	n := fs.Int("n", 1, "Number of mazes.")
+	seed := fs.Int64("seed", 1, "Random seed, the same seed gives the same mazes.")
+	out := fs.String("out", "", "Directory for fixtures, stdout if empty.")
+	_ = fs.Parse(args)
+
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			return err
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(*seed))
+	for i := 0; i < *n; i++ {
+		m, err := randomMaze(c, rnd)
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("gen_s%d_%03d", *seed, i)
+		if *out == "" {
+			if err := writeFixture(os.Stdout, name, m); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f, err := os.Create(filepath.Join(*out, name+".txt"))
+		if err != nil {
+			return err
+		}
+		if err := writeFixture(f, name, m); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tournamentCmd(args []string) error {
+	fs := flag.NewFlagSet("tournament", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	dir := fs.String("dir", "../fixtures", "Directory with *.txt fixtures.")
+	strategies := fs.String("strategies", allStrategies, "Comma separated solver strategies, each with optional flags.")
+	_ = fs.Parse(args)
+
+	ref, err := rf.referee()
+	if err != nil {
+		return err
+	}
+
+	entrants, err := parseEntrants(*strategies)
+	if err != nil {
+		return err
+	}
+
+	fixtures, err := loadFixtures(*dir)
+	if err != nil {
+		return err
+	}
+	return tournament(os.Stdout, ref, entrants, fixtures)
+}
+
+func trace(args []string) error {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	rf := registerRefereeFlags(fs)
+	mazePath := fs.String("maze", "", "Maze file to run the solver on.")
+	logPath := fs.String("log", "", "Solver's stderr recorded with CG_LOG=debug, instead of running -solver on -maze.")
+	turn := fs.Int("turn", 0, "Turn to start with.")
+	all := fs.Bool("all", false, "Print all turns from -turn on, without waiting for commands.")
+	_ = fs.Parse(args)
+
+	var log io.Reader
+	if *logPath != "" {
+		f, err := os.Open(*logPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		log = f
+	} else {
+		ref, err := rf.referee()
+		if err != nil {
+			return err
+		}
+		m, err := loadMaze(*mazePath)
+		if err != nil {
+			return err
+		}
+
+		buf := &bytes.Buffer{}
+		ref.env = []string{"CG_LOG=debug"}
+		ref.stderr = buf
+		res, err := ref.play(m)
+		if err != nil {
+			return err
+		}
+		fmt.Println(res)
+		log = buf
+	}
+
+	turns, err := parseTrace(log)
+	if err != nil {
+		return err
+	}
+	if !*all {
+		return view(os.Stdin, os.Stdout, turns, *turn)
+	}
+	for _, t := range turns {
+		if t.turn >= *turn {
+			renderTrace(os.Stdout, t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type pos struct {
+	row int
+	col int
+}
+
+// maze is a fully known Labyrinth test case:
+//
+//	R C A   (rows, columns, alarm rounds)
+//	R rows  ('#' wall, '.' hollow, 'T' start, 'C' control room)
+//
+// Lines starting with "//" are ignored.
+type maze struct {
+	rows        int
+	cols        int
+	alarmRounds int
+	cells       []string
+
+	start   pos
+	control pos
+}
+
+func loadMaze(path string) (maze, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return maze{}, err
+	}
+	defer f.Close()
+
+	m, err := parseMaze(f)
+	if err != nil {
+		return maze{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return m, nil
+}
+
+func parseMaze(r io.Reader) (maze, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := s.Err(); err != nil {
+		return maze{}, err
+	}
+	if len(lines) == 0 {
+		return maze{}, fmt.Errorf("empty maze")
+	}
+
+	var m maze
+	if _, err := fmt.Sscan(lines[0], &m.rows, &m.cols, &m.alarmRounds); err != nil {
+		return maze{}, fmt.Errorf("header %q: %v", lines[0], err)
+	}
+	if len(lines)-1 != m.rows {
+		return maze{}, fmt.Errorf("expected %d rows, got %d", m.rows, len(lines)-1)
+	}
+
+	starts, controls := 0, 0
+	for i, row := range lines[1:] {
+		if len(row) != m.cols {
+			return maze{}, fmt.Errorf("row %d: expected %d columns, got %d", i, m.cols, len(row))
+		}
+		if strings.Trim(row, "#.TC") != "" {
+			return maze{}, fmt.Errorf("row %d: only '#', '.', 'T' and 'C' are allowed, got %q", i, row)
+		}
+		if j := strings.IndexByte(row, 'T'); j >= 0 {
+			m.start = pos{row: i, col: j}
+			starts += strings.Count(row, "T")
+		}
+		if j := strings.IndexByte(row, 'C'); j >= 0 {
+			m.control = pos{row: i, col: j}
+			controls += strings.Count(row, "C")
+		}
+		m.cells = append(m.cells, row)
+	}
+	if starts != 1 || controls != 1 {
+		return maze{}, fmt.Errorf("expected exactly one 'T' and one 'C', got %d and %d", starts, controls)
+	}
+	return m, nil
+}
+
+func (m maze) at(p pos) byte {
+	if p.row < 0 || p.row >= m.rows || p.col < 0 || p.col >= m.cols {
+		return '#'
+	}
+	return m.cells[p.row][p.col]
+}
+
+func (m maze) String() string {
+	return fmt.Sprintf("%d %d %d\n%s\n", m.rows, m.cols, m.alarmRounds, strings.Join(m.cells, "\n"))
+}
+
+func (m maze) isOpen(p pos) bool {
+	return m.at(p) != '#'
+}
+
+// distances returns the length of the shortest path from the given field to every reachable one.
+func (m maze) distances(from pos) map[pos]int {
+	dist := map[pos]int{from: 0}
+	queue := []pos{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range moveDeltas {
+			n := pos{row: p.row + d.row, col: p.col + d.col}
+			if _, ok := dist[n]; ok || !m.isOpen(n) {
+				continue
+			}
+			dist[n] = dist[p] + 1
+			queue = append(queue, n)
+		}
+	}
+	return dist
+}
+
+// distance returns the length of the shortest path between two fields, -1 if there is none.
+func (m maze) distance(from, to pos) int {
+	if d, ok := m.distances(from)[to]; ok {
+		return d
+	}
+	return -1
+}
+
+// isSolvable returns true if Kirk knowing the whole maze upfront could win: the control room is reachable and the
+// shortest way back fits in alarm rounds. Exploration is up to the solver, so this does not mean every strategy wins.
+func (m maze) isSolvable() bool {
+	d := m.distance(m.control, m.start)
+	return d >= 0 && d <= m.alarmRounds && 2*d <= jetpackRounds
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type result struct {
+	won    bool
+	turns  int
+	reason string
+	moves  []string
+	// Rounds left before the alarm would go off, -1 if the alarm was never triggered.
+	alarmMargin int
+}
+
+func (r result) String() string {
+	if r.won {
+		return fmt.Sprintf("WIN after %d turns, alarm margin %d", r.turns, r.alarmMargin)
+	}
+	return fmt.Sprintf("LOSS after %d turns: %s", r.turns, r.reason)
+}
+
+type referee struct {
+	solver []string
+
+	firstTurnTimeout time.Duration
+	turnTimeout      time.Duration
+
+	// Extra environment for the solver, e.g. CG_LOG=debug.
+	env []string
+	// Solver's stderr goes here.
+	stderr io.Writer
+	// If not nil, every turn is reported here.
+	verbose io.Writer
+}
+
+// play runs a fresh solver process through the whole game. Solver failures (timeout, invalid command, wall hit,
+// crash) are a lost game, not an error. Error is returned only if the solver could not be started.
+func (ref referee) play(m maze) (result, error) {
+	cmd := exec.Command(ref.solver[0], ref.solver[1:]...)
+	cmd.Stderr = ref.stderr
+	if len(ref.env) > 0 {
+		cmd.Env = append(os.Environ(), ref.env...)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return result{}, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return result{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return result{}, fmt.Errorf("start solver %v: %v", ref.solver, err)
+	}
+	defer func() {
+		_ = stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		s := bufio.NewScanner(stdout)
+		for s.Scan() {
+			lines <- strings.TrimSpace(s.Text())
+		}
+	}()
+
+	g := newGame(m)
+	res := result{}
+	finish := func(reason string) (result, error) {
+		res.turns = g.turns
+		res.reason = reason
+		res.alarmMargin = -1
+		if g.alarmTurn >= 0 {
+			res.alarmMargin = g.alarmLeft()
+		}
+		return res, nil
+	}
+
+	// Writes can fail only if the solver is already gone, which is reported on the next read anyway.
+	_, _ = io.WriteString(stdin, m.initInput())
+	timeout := ref.firstTurnTimeout
+	for {
+		_, _ = io.WriteString(stdin, g.turnInput())
+
+		var move string
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return finish("solver exited")
+			}
+			move = line
+		case <-time.After(timeout):
+			return finish(fmt.Sprintf("timeout, no command within %v", timeout))
+		}
+		timeout = ref.turnTimeout
+
+		if err := g.apply(move); err != nil {
+			return finish(err.Error())
+		}
+		res.moves = append(res.moves, move)
+
+		if ref.verbose != nil {
+			alarm := "off"
+			if g.alarmTurn >= 0 {
+				alarm = fmt.Sprintf("%d left", g.alarmLeft())
+			}
+			fmt.Fprintf(ref.verbose, "Turn %d: %-5s Kirk at %d %d, fuel %d, alarm %s\n",
+				g.turns, move, g.kirk.row, g.kirk.col, g.fuel, alarm)
+		}
+
+		if over, won, reason := g.result(); over {
+			res.won = won
+			return finish(reason)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Kirk's jetpack has fuel for that many moves.
+	jetpackRounds = 1200
+	// Kirk scans that many cells around him in every direction, so a 5x5 window.
+	scanRadius = 2
+)
+
+var moves = map[string]pos{
+	"UP":    {row: -1},
+	"DOWN":  {row: 1},
+	"LEFT":  {col: -1},
+	"RIGHT": {col: 1},
+}
+
+// Same as moves, in a fixed order, so whatever iterates over them (e.g. the generator) is deterministic.
+var moveDeltas = []pos{moves["RIGHT"], moves["DOWN"], moves["LEFT"], moves["UP"]}
+
+// game is the referee state: the full maze, and the part of it Kirk has scanned, which is all the solver gets to see.
+type game struct {
+	maze
+
+	// What Kirk has scanned so far, '?' for unknown cells.
+	known [][]byte
+	kirk  pos
+	fuel  int
+	turns int
+	// Turn when Kirk reached the control room, -1 before.
+	alarmTurn int
+}
+
+func newGame(m maze) *game {
+	g := &game{maze: m, kirk: m.start, fuel: jetpackRounds, alarmTurn: -1}
+	for i := 0; i < m.rows; i++ {
+		g.known = append(g.known, []byte(strings.Repeat("?", m.cols)))
+	}
+	g.scan()
+	return g
+}
+
+func (g *game) scan() {
+	for r := g.kirk.row - scanRadius; r <= g.kirk.row+scanRadius; r++ {
+		for c := g.kirk.col - scanRadius; c <= g.kirk.col+scanRadius; c++ {
+			if r >= 0 && r < g.rows && c >= 0 && c < g.cols {
+				g.known[r][c] = g.cells[r][c]
+			}
+		}
+	}
+}
+
+// apply moves Kirk. It returns error if the move loses the game right away: not a valid command or a wall hit.
+func (g *game) apply(cmd string) error {
+	d, ok := moves[cmd]
+	if !ok {
+		return fmt.Errorf("invalid command %q", cmd)
+	}
+
+	next := pos{row: g.kirk.row + d.row, col: g.kirk.col + d.col}
+	if g.at(next) == '#' {
+		return fmt.Errorf("hit a wall moving %s from %v to %v", cmd, g.kirk, next)
+	}
+
+	g.kirk = next
+	g.fuel--
+	g.turns++
+	if g.alarmTurn < 0 && g.kirk == g.control {
+		g.alarmTurn = g.turns
+	}
+	g.scan()
+	return nil
+}
+
+// alarmLeft returns rounds left before the alarm goes off, Kirk has to be back at the start by the time it hits 0.
+// Meaningful only once the alarm is triggered.
+func (g *game) alarmLeft() int {
+	return g.alarmRounds - (g.turns - g.alarmTurn)
+}
+
+// result returns true if the game is over. Won is meaningful only then.
+func (g *game) result() (over bool, won bool, reason string) {
+	if g.alarmTurn >= 0 {
+		if g.kirk == g.start {
+			return true, true, ""
+		}
+		if g.alarmLeft() <= 0 {
+			return true, false, fmt.Sprintf("alarm expired, not back at the start within %d rounds", g.alarmRounds)
+		}
+	}
+	if g.fuel <= 0 {
+		return true, false, fmt.Sprintf("out of fuel after %d moves", jetpackRounds)
+	}
+	return false, false, ""
+}
+
+// turnInput is what the solver reads at the beginning of every turn.
+func (g *game) turnInput() string {
+	lines := []string{fmt.Sprintf("%d %d", g.kirk.row, g.kirk.col)}
+	for _, row := range g.known {
+		lines = append(lines, string(row))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// initInput is what the solver reads once, before the first turn.
+func (m maze) initInput() string {
+	return fmt.Sprintf("%d %d %d\n", m.rows, m.cols, m.alarmRounds)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// allStrategies are the solver's strategies as in its -strategy flag, see newStrategy in labirynth.go. Flags after the
+// name are passed to the solver as well.
+const allStrategies = "tremaux,tremaux -erase-marks,frontier,wall"
+
+// entrant is a strategy in the tournament, every entrant runs the same solver binary.
+type entrant struct {
+	name string
+	args []string
+
+	// Played fixtures which expect a win, wins and success rate are relative to them.
+	winnable int
+	wins     int
+	// Sums over wins only, a loss tells nothing about how good the way was. Every turn is a jetpack move, so turns
+	// are the fuel used as well.
+	turns  int
+	margin int
+	// Tightest alarm margin of a win, -1 if there is none.
+	minMargin int
+}
+
+// parseEntrants parses a comma separated list of strategies, e.g. "tremaux -erase-marks,frontier".
+func parseEntrants(s string) ([]entrant, error) {
+	var entrants []entrant
+	for _, e := range strings.Split(s, ",") {
+		fields := strings.Fields(e)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty strategy in %q", s)
+		}
+		entrants = append(entrants, entrant{
+			name:      strings.Join(fields, " "),
+			args:      append([]string{"-strategy"}, fields...),
+			minMargin: -1,
+		})
+	}
+	return entrants, nil
+}
+
+func (e *entrant) record(fix fixture, res result) {
+	if fix.expect == "win" {
+		e.winnable++
+	}
+	if !res.won {
+		return
+	}
+
+	e.wins++
+	e.turns += res.turns
+	e.margin += res.alarmMargin
+	if e.minMargin < 0 || res.alarmMargin < e.minMargin {
+		e.minMargin = res.alarmMargin
+	}
+}
+
+// tournament plays every fixture with every entrant and writes a row per game, then a standing per entrant. Wins and
+// success rate are of the fixtures which can be won.
+func tournament(w io.Writer, ref referee, entrants []entrant, fixtures []fixture) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIXTURE\tEXPECT\tSTRATEGY\tRESULT\tTURNS (= FUEL USED)\tALARM MARGIN\t")
+	solver := ref.solver
+	for _, fix := range fixtures {
+		for i := range entrants {
+			e := &entrants[i]
+			ref.solver = append(append([]string{}, solver...), e.args...)
+			res, err := ref.play(fix.maze)
+			if err != nil {
+				return fmt.Errorf("%s with %s: %v", fix.name, e.name, err)
+			}
+			e.record(fix, res)
+
+			name, expect := fix.name, fix.expect
+			if i > 0 {
+				name, expect = "", ""
+			}
+			outcome := "WIN"
+			if !res.won {
+				outcome = "LOSS: " + res.reason
+			}
+			margin := "-"
+			if res.alarmMargin >= 0 {
+				margin = fmt.Sprint(res.alarmMargin)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t\n", name, expect, e.name, outcome, res.turns, margin)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STRATEGY\tWINS\tSUCCESS RATE\tAVG TURNS (= FUEL USED)\tAVG ALARM MARGIN\tMIN ALARM MARGIN\t")
+	for _, e := range entrants {
+		rate := 0.0
+		if e.winnable > 0 {
+			rate = 100 * float64(e.wins) / float64(e.winnable)
+		}
+		if e.wins == 0 {
+			fmt.Fprintf(tw, "%s\t0/%d winnable\t%.0f%%\t-\t-\t-\t\n", e.name, e.winnable, rate)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d/%d winnable\t%.0f%%\t%.1f\t%.1f\t%d\t\n", e.name, e.wins, e.winnable, rate,
+			float64(e.turns)/float64(e.wins), float64(e.margin)/float64(e.wins), e.minMargin)
+	}
+	return tw.Flush()
+}
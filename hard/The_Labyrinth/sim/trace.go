@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// traceTurn is what the runner knew when it chose the move, parsed from its 'TRACE' debug line.
+type traceTurn struct {
+	turn  int
+	kirk  pos
+	dir   string
+	known []string
+	// Trémaux marks per passage, in RIGHT, DOWN, LEFT, UP order.
+	marks map[pos][4]int
+	dist  map[pos]int
+	// All other solver log lines of this turn.
+	logs []string
+}
+
+// parseTrace reads the solver's stderr, recorded with CG_LOG=debug. Lines are '[turn] message', see logf in
+// labirynth.go.
+func parseTrace(r io.Reader) ([]traceTurn, error) {
+	var (
+		turns []traceTurn
+		logs  = map[int][]string{}
+	)
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		// The prefix is at least 3 digits, turns from 1000 on have 4.
+		line := s.Text()
+		end := strings.IndexByte(line, ']')
+		if !strings.HasPrefix(line, "[") || end < 0 {
+			continue
+		}
+		turn, err := strconv.Atoi(line[1:end])
+		if err != nil {
+			continue
+		}
+
+		msg := strings.TrimPrefix(line[end+1:], " ")
+		if !strings.HasPrefix(msg, "TRACE ") {
+			logs[turn] = append(logs[turn], msg)
+			continue
+		}
+
+		t, err := parseTraceLine(strings.TrimPrefix(msg, "TRACE "))
+		if err != nil {
+			return nil, fmt.Errorf("turn %d: %v", turn, err)
+		}
+		t.turn = turn
+		turns = append(turns, t)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range turns {
+		turns[i].logs = logs[turns[i].turn]
+	}
+	return turns, nil
+}
+
+func parseTraceLine(line string) (traceTurn, error) {
+	t := traceTurn{marks: map[pos][4]int{}, dist: map[pos]int{}}
+	for _, field := range strings.Fields(line) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return traceTurn{}, fmt.Errorf("expected key=value, got %q", field)
+		}
+
+		key, value := kv[0], kv[1]
+		switch key {
+		case "kirk":
+			p, err := parsePos(value)
+			if err != nil {
+				return traceTurn{}, err
+			}
+			t.kirk = p
+		case "dir":
+			t.dir = value
+		case "map":
+			t.known = strings.Split(value, "/")
+		case "marks", "dist":
+			if value == "" {
+				continue
+			}
+			for _, entry := range strings.Split(value, ";") {
+				pv := strings.SplitN(entry, ":", 2)
+				if len(pv) != 2 {
+					return traceTurn{}, fmt.Errorf("%s: expected pos:value, got %q", key, entry)
+				}
+				p, err := parsePos(pv[0])
+				if err != nil {
+					return traceTurn{}, err
+				}
+				nums, err := parseInts(pv[1])
+				if err != nil {
+					return traceTurn{}, err
+				}
+
+				switch {
+				case key == "dist" && len(nums) == 1:
+					t.dist[p] = nums[0]
+				case key == "marks" && len(nums) == 4:
+					t.marks[p] = [4]int{nums[0], nums[1], nums[2], nums[3]}
+				default:
+					return traceTurn{}, fmt.Errorf("%s: unexpected value %q", key, pv[1])
+				}
+			}
+		}
+	}
+	if len(t.known) == 0 {
+		return traceTurn{}, fmt.Errorf("no map in %q", line)
+	}
+	return t, nil
+}
+
+func parsePos(s string) (pos, error) {
+	nums, err := parseInts(s)
+	if err != nil || len(nums) != 2 {
+		return pos{}, fmt.Errorf("expected row,col, got %q", s)
+	}
+	return pos{row: nums[0], col: nums[1]}, nil
+}
+
+func parseInts(s string) ([]int, error) {
+	var nums []int
+	for _, n := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(n)
+		if err != nil {
+			return nil, err
+		}
+		nums = append(nums, i)
+	}
+	return nums, nil
+}
+
+// renderTrace draws the known map twice, side by side. On the left every field is followed by the marks of the
+// passage to the right and has the marks of the passage down below it:
+//
+//	T1.2.   start, 1 mark to the right, then 2 marks
+//	1 ? #   1 mark down from start
+//
+// On the right there is every field's minDist, '###' for walls and '  ?' for not scanned fields. Kirk is 'K' on both.
+func renderTrace(w io.Writer, t traceTurn) {
+	fmt.Fprintf(w, "Turn %d: Kirk at %d %d goes %s\n", t.turn, t.kirk.row, t.kirk.col, t.dir)
+
+	markChar := func(m int) byte {
+		switch {
+		case m <= 0:
+			return ' '
+		case m > 9:
+			return '+'
+		}
+		return byte('0' + m)
+	}
+
+	for i, row := range t.known {
+		var fields, passages, dists strings.Builder
+		for j := range row {
+			p := pos{row: i, col: j}
+			c := row[j]
+			if p == t.kirk {
+				c = 'K'
+			}
+			m := t.marks[p]
+
+			fields.WriteByte(c)
+			fields.WriteByte(markChar(m[0]))
+			passages.WriteByte(markChar(m[1]))
+			passages.WriteByte(' ')
+
+			switch d, ok := t.dist[p]; {
+			case p == t.kirk:
+				dists.WriteString("  K")
+			case ok:
+				fmt.Fprintf(&dists, "%3d", d%1000)
+			case row[j] == '#':
+				dists.WriteString("###")
+			case row[j] == '?':
+				dists.WriteString("  ?")
+			default:
+				fmt.Fprintf(&dists, "%3c", row[j])
+			}
+		}
+		fmt.Fprintf(w, "%s   %s\n%s\n", fields.String(), dists.String(), passages.String())
+	}
+	for _, l := range t.logs {
+		fmt.Fprintln(w, "  "+l)
+	}
+}
+
+// view steps through turns with commands read from in: empty line or 'n' for the next turn, 'p' for the previous
+// one, 'g <turn>' to go to a turn and 'q' to quit.
+func view(in io.Reader, out io.Writer, turns []traceTurn, start int) error {
+	if len(turns) == 0 {
+		return fmt.Errorf("no TRACE lines, was the solver run with CG_LOG=debug?")
+	}
+
+	byTurn := map[int]int{}
+	for i, t := range turns {
+		byTurn[t.turn] = i
+	}
+
+	i := 0
+	if j, ok := byTurn[start]; ok {
+		i = j
+	}
+
+	s := bufio.NewScanner(in)
+	for {
+		renderTrace(out, turns[i])
+		fmt.Fprintf(out, "[%d/%d] n(ext), p(rev), g(oto) <turn>, q(uit): ", i+1, len(turns))
+		if !s.Scan() {
+			fmt.Fprintln(out)
+			return s.Err()
+		}
+
+		cmd := strings.Fields(s.Text())
+		switch {
+		case len(cmd) == 0 || cmd[0] == "n":
+			if i < len(turns)-1 {
+				i++
+			}
+		case cmd[0] == "p":
+			if i > 0 {
+				i--
+			}
+		case cmd[0] == "g" && len(cmd) == 2:
+			turn, err := strconv.Atoi(cmd[1])
+			j, ok := byTurn[turn]
+			if err != nil || !ok {
+				fmt.Fprintf(out, "No turn %q.\n", cmd[1])
+				continue
+			}
+			i = j
+		case cmd[0] == "q":
+			return nil
+		default:
+			fmt.Fprintf(out, "Unknown command %q.\n", s.Text())
+		}
+	}
+}
@@ -15,14 +15,49 @@ const (
 func main() {
 	defer func() {
 		if r := recover(); r != nil {
-			d("ERROR: %v", r)
+			logf(lvlError, "ERROR: %v", r)
 		}
 	}()
 	(&lander{}).Land()
 }
 
-func d(format string, a ...interface{}) {
-	fmt.Fprintln(os.Stderr, fmt.Sprintf(format, a...))
+// Leveled logging. CodinGame takes a single file, so this block is copied as is into every solver.
+//
+// Only errors are logged by default, so nothing floods stderr on submission. Run with CG_LOG=info or CG_LOG=debug
+// locally, or bump logDefault for the CodinGame IDE.
+type logLevel int
+
+const (
+	lvlError logLevel = iota
+	lvlInfo
+	lvlDebug
+
+	logDefault = lvlError
+)
+
+var (
+	logLvl = parseLogLevel(os.Getenv("CG_LOG"))
+	// Every line is prefixed with it, solvers bump it every turn.
+	logTurn = 0
+)
+
+func parseLogLevel(lvl string) logLevel {
+	switch lvl {
+	case "error":
+		return lvlError
+	case "info":
+		return lvlInfo
+	case "debug":
+		return lvlDebug
+	}
+	return logDefault
+}
+
+func logf(lvl logLevel, format string, a ...interface{}) {
+	if lvl > logLvl {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%03d] %s\n", logTurn, fmt.Sprintf(format, a...))
 }
 
 type lander struct {
@@ -39,20 +74,20 @@ type lander struct {
 // Land using PID controller approach. In every iteration check the estimated landing and adjust.
 func (l *lander) Land() {
 	l.discoverSurfaceAndLandingSite()
-	d("Landing center: %s, tolerance: %d", l.landingCenterPoint.print(), l.landingSiteTolerance)
+	logf(lvlDebug, "Landing center: %s, tolerance: %d", l.landingCenterPoint.print(), l.landingSiteTolerance)
 
 	// Adjusting loop.
 	for {
 		l.gatherInput()
 
 		where, isLandingArea, when, eVSpeed, eHSpeed := l.estimateSurfaceReachable()
-		d("Estimated landing: %s | ok? %v | epochs: %d, eV: %f, eH %f",
+		logf(lvlDebug, "Estimated landing: %s | ok? %v | epochs: %d, eV: %f, eH %f",
 			where.print(), isLandingArea, when, eVSpeed, eHSpeed)
 
 		// TODO: Calculate obstacles, use bezier.
 
 		angleToAdjust, distance := l.angleAndDistanceToTarget(where)
-		d("Angle to adjust: %f | distance %f", angleToAdjust, distance)
+		logf(lvlDebug, "Angle to adjust: %f | distance %f", angleToAdjust, distance)
 
 		// Adjusting phase.
 		throttle := 4
@@ -63,7 +98,7 @@ func (l *lander) Land() {
 		// We are free-falling to Landing Area, cool - but we need to brake ):
 		if isLandingArea && when < 20 && (math.Abs(float64(eHSpeed)) >= MaxHSpeed || math.Abs(float64(eVSpeed)) >= MaxVSpeed) {
 			brakingVec := newPoint(-l.hSpeed, -l.vSpeed)
-			d("braking: %s", brakingVec.print())
+			logf(lvlDebug, "braking: %s", brakingVec.print())
 			angleToAdjust = l.pos.Sub(where).Angle(brakingVec)
 
 			throttle = 4
@@ -83,7 +118,7 @@ func (l *lander) landingPhase(landingTarget point) {
 		// We are free-falling to Landing Area, cool - but we need to brake ):
 		if math.Abs(float64(l.hSpeed)) >= MaxHSpeed || math.Abs(float64(l.vSpeed)) >= MaxVSpeed {
 			brakingVec := newPoint(-l.hSpeed, -l.vSpeed)
-			d("braking: %s", brakingVec.print())
+			logf(lvlDebug, "braking: %s", brakingVec.print())
 			angleToAdjust = l.pos.Sub(landingTarget).Angle(brakingVec)
 
 			throttle = 4
@@ -92,7 +127,7 @@ func (l *lander) landingPhase(landingTarget point) {
 			}
 		} else {
 			angleToAdjust, distance := l.angleAndDistanceToTarget(landingTarget)
-			d("Angle to adjust: %f | distance %f", angleToAdjust, distance)
+			logf(lvlDebug, "Angle to adjust: %f | distance %f", angleToAdjust, distance)
 
 			if angleToAdjust > 5 {
 				throttle = 4
@@ -115,7 +150,7 @@ func (l *lander) angleAndDistanceToTarget(currentTarget point) (angle float64, d
 	if l.landingCenterPoint.x < currentTarget.x {
 		angle = desiredDir.Angle(headingDir)
 	} else {
-		d("TOO FAR")
+		logf(lvlDebug, "TOO FAR")
 		angle = headingDir.Angle(desiredDir)
 	}
 
@@ -156,6 +191,7 @@ func (l *lander) gatherInput() {
 	// power: the thrust power (0 to 4).
 	var X, Y int
 	fmt.Scan(&X, &Y, &l.hSpeed, &l.vSpeed, &l.fuel, &l.rotation, &l.power)
+	logTurn++
 	l.pos = newPoint(X, Y)
 }
 
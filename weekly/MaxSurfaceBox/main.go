@@ -7,6 +7,45 @@ import (
 	"sort"
 )
 
+// Leveled logging. CodinGame takes a single file, so this block is copied as is into every solver.
+//
+// Only errors are logged by default, so nothing floods stderr on submission. Run with CG_LOG=info or CG_LOG=debug
+// locally, or bump logDefault for the CodinGame IDE.
+type logLevel int
+
+const (
+	lvlError logLevel = iota
+	lvlInfo
+	lvlDebug
+
+	logDefault = lvlError
+)
+
+var (
+	logLvl = parseLogLevel(os.Getenv("CG_LOG"))
+	// Every line is prefixed with it, solvers bump it every turn.
+	logTurn = 0
+)
+
+func parseLogLevel(lvl string) logLevel {
+	switch lvl {
+	case "error":
+		return lvlError
+	case "info":
+		return lvlInfo
+	case "debug":
+		return lvlDebug
+	}
+	return logDefault
+}
+
+func logf(lvl logLevel, format string, a ...interface{}) {
+	if lvl > logLvl {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%03d] %s\n", logTurn, fmt.Sprintf(format, a...))
+}
+
 var bricksNum int
 
 func main() {
@@ -169,11 +208,11 @@ func tolerantIsInt(number float64) (int, bool) {
 }
 
 func logMin(m match) match {
-	fmt.Fprintln(os.Stderr, fmt.Sprintf("Minimum: %d x %d x %d", m.x, m.y, m.z))
+	logf(lvlInfo, "Minimum: %d x %d x %d", m.x, m.y, m.z)
 	return m
 }
 
 func logMax(m match) match {
-	fmt.Fprintln(os.Stderr, fmt.Sprintf("Maximum: %d x %d x %d", m.x, m.y, m.z))
+	logf(lvlInfo, "Maximum: %d x %d x %d", m.x, m.y, m.z)
 	return m
 }